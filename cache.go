@@ -0,0 +1,184 @@
+package code
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type cacheEntry struct {
+	key      string
+	master   string
+	models   []Model
+	expires  time.Time
+	boundary time.Time
+}
+type loadCall struct {
+	done     chan struct{}
+	models   []Model
+	boundary time.Time
+	stale    bool
+	err      error
+}
+type CacheLoader struct {
+	Codes   func(ctx context.Context, master string) ([]Model, error)
+	TTL     time.Duration
+	TTLs    map[string]time.Duration
+	Size    int
+	mu      sync.Mutex
+	lru     *list.List
+	entries map[string]*list.Element
+	calls   map[string]*loadCall
+	version uint64
+}
+
+func NewCacheLoader(load func(ctx context.Context, master string) ([]Model, error), ttl time.Duration, options ...int) *CacheLoader {
+	var size int
+	if len(options) > 0 && options[0] > 0 {
+		size = options[0]
+	}
+	return &CacheLoader{Codes: load, TTL: ttl, TTLs: make(map[string]time.Duration), Size: size, lru: list.New(), entries: make(map[string]*list.Element), calls: make(map[string]*loadCall)}
+}
+func (c *CacheLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	if _, ok := GetAsOf(ctx); ok {
+		return c.Codes(ctx, master)
+	}
+	key := cacheKey(ctx, master)
+	c.mu.Lock()
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*cacheEntry)
+		if time.Now().Before(entry.expires) {
+			c.lru.MoveToFront(e)
+			c.mu.Unlock()
+			setBoundary(ctx, entry.boundary)
+			return copyModels(entry.models), nil
+		}
+		c.lru.Remove(e)
+		delete(c.entries, key)
+	}
+	call, ok := c.calls[key]
+	if !ok {
+		call = &loadCall{done: make(chan struct{})}
+		c.calls[key] = call
+		lctx, cancel := detach(ctx)
+		go func(version uint64) {
+			defer cancel()
+			c.load(lctx, key, master, call, version)
+		}(c.version)
+	}
+	c.mu.Unlock()
+	select {
+	case <-call.done:
+		if call.err != nil {
+			return nil, call.err
+		}
+		if call.stale {
+			markStale(ctx)
+		}
+		setBoundary(ctx, call.boundary)
+		return copyModels(call.models), nil
+	case <-ctx.Done():
+		return nil, ClassifyError(ctx.Err())
+	}
+}
+func (c *CacheLoader) load(ctx context.Context, key string, master string, call *loadCall, version uint64) {
+	// the stale flag of the load is not the flag of the client who starts it, so that all the clients of the call are marked stale
+	sctx, stale := WithStaleFlag(ctx)
+	bctx, boundary := WithBoundary(sctx)
+	models, err := c.Codes(bctx, master)
+	c.mu.Lock()
+	delete(c.calls, key)
+	if err == nil {
+		setBoundary(bctx, NextBoundary(models, time.Now()))
+		// the stale codes are not cached, else they would be served for the TTL after the source is refreshed
+		if version == c.version && !*stale {
+			c.set(key, master, models, *boundary)
+		}
+	}
+	call.models, call.boundary, call.stale, call.err = models, *boundary, *stale, err
+	c.mu.Unlock()
+	close(call.done)
+}
+
+// detach keeps the values and the deadline of the context, but not its cancellation, so that the client who starts a load does not cancel it for the others.
+func detach(ctx context.Context) (context.Context, context.CancelFunc) {
+	d := context.WithoutCancel(ctx)
+	if deadline, ok := ctx.Deadline(); ok {
+		return context.WithDeadline(d, deadline)
+	}
+	return d, func() {}
+}
+
+// cacheKey returns the key of the codes of the master in the locale of the context, as the names can be localized.
+func cacheKey(ctx context.Context, master string) string {
+	if locale := GetLocale(ctx); len(locale) > 0 {
+		return master + "\x00" + locale
+	}
+	return master
+}
+func (c *CacheLoader) Remove(master string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	for key, e := range c.entries {
+		if e.Value.(*cacheEntry).master == master {
+			c.lru.Remove(e)
+			delete(c.entries, key)
+		}
+	}
+}
+func (c *CacheLoader) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.version++
+	c.lru.Init()
+	c.entries = make(map[string]*list.Element)
+}
+func (c *CacheLoader) set(key string, master string, models []Model, boundary time.Time) {
+	ttl := c.TTL
+	if t, ok := c.TTLs[master]; ok {
+		ttl = t
+	}
+	if ttl <= 0 {
+		return
+	}
+	expires := time.Now().Add(ttl)
+	if !boundary.IsZero() && boundary.Before(expires) {
+		expires = boundary
+	}
+	entry := &cacheEntry{key: key, master: master, models: models, expires: expires, boundary: boundary}
+	c.entries[key] = c.lru.PushFront(entry)
+	for c.Size > 0 && c.lru.Len() > c.Size {
+		last := c.lru.Back()
+		c.lru.Remove(last)
+		delete(c.entries, last.Value.(*cacheEntry).key)
+	}
+}
+
+// copyModels copies the models deeply, so that the callers, such as LocaleLoader and the trees, cannot change the cached codes.
+func copyModels(models []Model) []Model {
+	result := make([]Model, len(models))
+	for i, m := range models {
+		if m.Attributes != nil {
+			attributes := make(map[string]interface{}, len(m.Attributes))
+			for k, v := range m.Attributes {
+				attributes[k] = v
+			}
+			m.Attributes = attributes
+		}
+		if m.ValidFrom != nil {
+			t := *m.ValidFrom
+			m.ValidFrom = &t
+		}
+		if m.ValidTo != nil {
+			t := *m.ValidTo
+			m.ValidTo = &t
+		}
+		if m.Children != nil {
+			m.Children = copyModels(m.Children)
+		}
+		result[i] = m
+	}
+	return result
+}
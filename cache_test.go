@@ -0,0 +1,145 @@
+package code
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countLoader struct {
+	calls int32
+	delay time.Duration
+}
+
+func (l *countLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	n := atomic.AddInt32(&l.calls, 1)
+	if l.delay > 0 {
+		select {
+		case <-time.After(l.delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	name := master
+	if locale := GetLocale(ctx); len(locale) > 0 {
+		name = master + " " + locale
+	}
+	return []Model{{Code: master, Name: name, Sequence: n, Attributes: map[string]interface{}{"n": n}}}, nil
+}
+func (l *countLoader) count() int {
+	return int(atomic.LoadInt32(&l.calls))
+}
+
+func TestCacheLoaderTTL(t *testing.T) {
+	loader := &countLoader{}
+	c := NewCacheLoader(loader.Load, 50*time.Millisecond)
+	c.TTLs["short"] = time.Millisecond
+	ctx := context.Background()
+	c.Load(ctx, "gender")
+	c.Load(ctx, "gender")
+	if loader.count() != 1 {
+		t.Fatalf("calls = %d, want the second load from the cache", loader.count())
+	}
+	time.Sleep(60 * time.Millisecond)
+	c.Load(ctx, "gender")
+	if loader.count() != 2 {
+		t.Errorf("calls = %d, want a load after the TTL", loader.count())
+	}
+	c.Load(ctx, "short")
+	time.Sleep(5 * time.Millisecond)
+	c.Load(ctx, "short")
+	if loader.count() != 4 {
+		t.Errorf("calls = %d, want a load after the TTL of the master", loader.count())
+	}
+	c.Remove("gender")
+	c.Load(ctx, "gender")
+	if loader.count() != 5 {
+		t.Errorf("calls = %d, want a load after Remove", loader.count())
+	}
+}
+
+func TestCacheLoaderLRU(t *testing.T) {
+	loader := &countLoader{}
+	c := NewCacheLoader(loader.Load, time.Minute, 2)
+	ctx := context.Background()
+	c.Load(ctx, "a")
+	c.Load(ctx, "b")
+	c.Load(ctx, "a")
+	c.Load(ctx, "c")
+	c.Load(ctx, "a")
+	if loader.count() != 3 {
+		t.Errorf("calls = %d, want the recently used master in the cache", loader.count())
+	}
+	c.Load(ctx, "b")
+	if loader.count() != 4 {
+		t.Errorf("calls = %d, want the least recently used master evicted", loader.count())
+	}
+}
+
+func TestCacheLoaderCoalescing(t *testing.T) {
+	loader := &countLoader{delay: 50 * time.Millisecond}
+	c := NewCacheLoader(loader.Load, time.Minute)
+	leader, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var wg sync.WaitGroup
+	errs := make([]error, 5)
+	for i := range errs {
+		ctx := context.Background()
+		if i == 0 {
+			ctx = leader
+		}
+		wg.Add(1)
+		go func(i int, ctx context.Context) {
+			defer wg.Done()
+			_, errs[i] = c.Load(ctx, "gender")
+		}(i, ctx)
+		if i == 0 {
+			time.Sleep(5 * time.Millisecond)
+			cancel()
+		}
+	}
+	wg.Wait()
+	if loader.count() != 1 {
+		t.Errorf("calls = %d, want one load for the concurrent requests", loader.count())
+	}
+	if errs[0] == nil {
+		t.Error("the canceled request, want an error")
+	}
+	for i := 1; i < len(errs); i++ {
+		if errs[i] != nil {
+			t.Errorf("request %d error = %v, want the codes of the load of the canceled request", i, errs[i])
+		}
+	}
+}
+
+func TestCacheLoaderLocale(t *testing.T) {
+	loader := &countLoader{}
+	c := NewCacheLoader(loader.Load, time.Minute)
+	ctx := context.Background()
+	en, _ := c.Load(WithLocale(ctx, "en"), "gender")
+	fr, _ := c.Load(WithLocale(ctx, "fr"), "gender")
+	if en[0].Name != "gender en" || fr[0].Name != "gender fr" {
+		t.Errorf("names = %q, %q, want the codes of each locale", en[0].Name, fr[0].Name)
+	}
+	c.Remove("gender")
+	c.Load(WithLocale(ctx, "fr"), "gender")
+	if loader.count() != 3 {
+		t.Errorf("calls = %d, want Remove to remove all locales", loader.count())
+	}
+}
+
+func TestCacheLoaderCopy(t *testing.T) {
+	loader := &countLoader{}
+	c := NewCacheLoader(loader.Load, time.Minute)
+	ctx := context.Background()
+	models, _ := c.Load(ctx, "gender")
+	models[0].Name = "changed"
+	models[0].Attributes["n"] = "changed"
+	models[0].Children = append(models[0].Children, Model{Code: "child"})
+	models, _ = c.Load(ctx, "gender")
+	if models[0].Name != "gender" || models[0].Attributes["n"] != int32(1) || len(models[0].Children) != 0 {
+		t.Errorf("cached = %+v, want the codes not changed by the caller", models[0])
+	}
+}
@@ -0,0 +1,141 @@
+package code
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+type HttpLoader struct {
+	Client         *http.Client
+	Url            string
+	RequiredMaster bool
+	Id             string
+	Name           string
+}
+
+func NewHttpLoader(client *http.Client, url string, options ...HandlerConfig) *HttpLoader {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	l := &HttpLoader{Client: client, Url: strings.TrimSuffix(url, "/"), RequiredMaster: true}
+	if len(options) > 0 {
+		c := options[0]
+		if c.Master != nil {
+			l.RequiredMaster = *c.Master
+		}
+		l.Id = c.Id
+		l.Name = c.Name
+	}
+	return l
+}
+func (l HttpLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	u := l.Url
+	if l.RequiredMaster {
+		u = u + "/" + url.PathEscape(master)
+	}
+	if len(l.Id) == 0 && len(l.Name) == 0 {
+		models := make([]Model, 0)
+		err := doJson(ctx, l.Client, http.MethodGet, u, nil, &models)
+		return models, err
+	}
+	rs := make([]map[string]string, 0)
+	if err := doJson(ctx, l.Client, http.MethodGet, u, nil, &rs); err != nil {
+		return nil, err
+	}
+	models := make([]Model, 0)
+	for _, r := range rs {
+		models = append(models, Model{Id: r[l.Id], Name: r[l.Name]})
+	}
+	return models, nil
+}
+
+type HttpQuery struct {
+	Client   *http.Client
+	QueryUrl string
+	LoadUrl  string
+	Keyword  string
+	Max      string
+}
+
+func NewHttpQuery(client *http.Client, queryUrl string, loadUrl string, opts ...string) *HttpQuery {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	keyword := "q"
+	if len(opts) > 0 && len(opts[0]) > 0 {
+		keyword = opts[0]
+	}
+	max := "max"
+	if len(opts) > 1 && len(opts[1]) > 0 {
+		max = opts[1]
+	}
+	return &HttpQuery{Client: client, QueryUrl: queryUrl, LoadUrl: loadUrl, Keyword: keyword, Max: max}
+}
+func (q HttpQuery) Query(ctx context.Context, key string, max int64) ([]Model, error) {
+	ps := url.Values{}
+	ps.Set(q.Keyword, key)
+	if max > 0 {
+		ps.Set(q.Max, strconv.FormatInt(max, 10))
+	}
+	u := q.QueryUrl
+	if strings.Contains(u, "?") {
+		u = u + "&" + ps.Encode()
+	} else {
+		u = u + "?" + ps.Encode()
+	}
+	models := make([]Model, 0)
+	err := doJson(ctx, q.Client, http.MethodGet, u, nil, &models)
+	return models, err
+}
+func (q HttpQuery) Load(ctx context.Context, keys []string) ([]Model, error) {
+	models := make([]Model, 0)
+	if len(keys) == 0 {
+		return models, nil
+	}
+	body, err := json.Marshal(keys)
+	if err != nil {
+		return nil, err
+	}
+	err = doJson(ctx, q.Client, http.MethodPost, q.LoadUrl, bytes.NewReader(body), &models)
+	return models, err
+}
+
+func doJson(ctx context.Context, client *http.Client, method string, u string, body io.Reader, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return ClassifyError(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		b, _ := ioutil.ReadAll(io.LimitReader(res.Body, 512))
+		err = fmt.Errorf("%s %s returned status %d: %s", method, u, res.StatusCode, strings.TrimSpace(string(b)))
+		switch res.StatusCode {
+		case http.StatusBadRequest:
+			return &KindError{Kind: ErrInvalidInput, Err: err, Message: ErrInvalidInput.Error()}
+		case http.StatusNotFound:
+			return &KindError{Kind: ErrUnknownMaster, Err: err, Message: ErrUnknownMaster.Error()}
+		case http.StatusBadGateway, http.StatusServiceUnavailable:
+			return &KindError{Kind: ErrUnavailable, Err: err}
+		case http.StatusGatewayTimeout:
+			return &KindError{Kind: ErrTimeout, Err: err}
+		}
+		return err
+	}
+	return json.NewDecoder(res.Body).Decode(result)
+}
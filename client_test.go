@@ -0,0 +1,79 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHttpLoader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/codes/gender":
+			w.Write([]byte(`[{"code":"F","name":"Female"}]`))
+		case "/rows/gender":
+			w.Write([]byte(`[{"key":"F","label":"Female"}]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	l := NewHttpLoader(nil, server.URL+"/codes/")
+	models, err := l.Load(context.Background(), "gender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Code != "F" || models[0].Name != "Female" {
+		t.Errorf("Load = %+v, want the codes of the master", models)
+	}
+
+	l = NewHttpLoader(nil, server.URL+"/rows", HandlerConfig{Id: "key", Name: "label"})
+	models, err = l.Load(context.Background(), "gender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Id != "F" || models[0].Name != "Female" {
+		t.Errorf("Load = %+v, want the mapped fields", models)
+	}
+}
+
+func TestHttpQuery(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			json.NewEncoder(w).Encode([]Model{{Code: r.URL.Query().Get("keyword"), Name: r.URL.Query().Get("limit")}})
+		case http.MethodPost:
+			var keys []string
+			json.NewDecoder(r.Body).Decode(&keys)
+			models := make([]Model, 0)
+			for _, key := range keys {
+				models = append(models, Model{Code: key})
+			}
+			json.NewEncoder(w).Encode(models)
+		}
+	}))
+	defer server.Close()
+
+	q := NewHttpQuery(nil, server.URL+"/query?type=x", server.URL+"/load", "keyword", "limit")
+	models, err := q.Query(context.Background(), "ab", 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].Code != "ab" || models[0].Name != "5" {
+		t.Errorf("Query = %+v, want the keyword and the max in the query string", models)
+	}
+	models, err = q.Load(context.Background(), []string{"A", "B"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(models); !equalStrings(codes, []string{"A", "B"}) {
+		t.Errorf("Load = %v, want the posted keys", codes)
+	}
+	models, err = q.Load(context.Background(), nil)
+	if err != nil || len(models) != 0 {
+		t.Errorf("Load without keys = %v, %v, want no codes", models, err)
+	}
+}
@@ -6,70 +6,108 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
-	"regexp"
-	"strconv"
 	"strings"
+	"time"
 )
 
 const (
-	driverPostgres   = "postgres"
-	driverMysql      = "mysql"
-	driverMssql      = "mssql"
-	driverOracle     = "oracle"
-	driverSqlite3    = "sqlite3"
-	driverNotSupport = "no support"
+	driverPostgres = "postgres"
+	driverMysql    = "mysql"
+	driverMssql    = "mssql"
+	driverOracle   = "oracle"
+	driverSqlite3  = "sqlite3"
 )
 
 type Model struct {
-	Id       string `yaml:"id" mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
-	Code     string `yaml:"code" mapstructure:"code" json:"code,omitempty" gorm:"column:code" bson:"code,omitempty" dynamodbav:"code,omitempty" firestore:"code,omitempty"`
-	Value    string `yaml:"value" mapstructure:"value" json:"value,omitempty" gorm:"column:value" bson:"value,omitempty" dynamodbav:"value,omitempty" firestore:"value,omitempty"`
-	Name     string `yaml:"name" mapstructure:"name" json:"name,omitempty" gorm:"column:name" bson:"name,omitempty" dynamodbav:"name,omitempty" firestore:"name,omitempty"`
-	Text     string `yaml:"text" mapstructure:"text" json:"text,omitempty" gorm:"column:text" bson:"text,omitempty" dynamodbav:"text,omitempty" firestore:"text,omitempty"`
-	Sequence int32  `yaml:"sequence" mapstructure:"sequence" json:"sequence,omitempty" gorm:"column:sequence" bson:"sequence,omitempty" dynamodbav:"sequence,omitempty" firestore:"sequence,omitempty"`
+	Id         string                 `yaml:"id" mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
+	Code       string                 `yaml:"code" mapstructure:"code" json:"code,omitempty" gorm:"column:code" bson:"code,omitempty" dynamodbav:"code,omitempty" firestore:"code,omitempty"`
+	Value      string                 `yaml:"value" mapstructure:"value" json:"value,omitempty" gorm:"column:value" bson:"value,omitempty" dynamodbav:"value,omitempty" firestore:"value,omitempty"`
+	Name       string                 `yaml:"name" mapstructure:"name" json:"name,omitempty" gorm:"column:name" bson:"name,omitempty" dynamodbav:"name,omitempty" firestore:"name,omitempty"`
+	Text       string                 `yaml:"text" mapstructure:"text" json:"text,omitempty" gorm:"column:text" bson:"text,omitempty" dynamodbav:"text,omitempty" firestore:"text,omitempty"`
+	Sequence   int32                  `yaml:"sequence" mapstructure:"sequence" json:"sequence,omitempty" gorm:"column:sequence" bson:"sequence,omitempty" dynamodbav:"sequence,omitempty" firestore:"sequence,omitempty"`
+	Parent     string                 `yaml:"parent" mapstructure:"parent" json:"parent,omitempty" gorm:"column:parent" bson:"parent,omitempty" dynamodbav:"parent,omitempty" firestore:"parent,omitempty"`
+	ValidFrom  *time.Time             `yaml:"valid_from" mapstructure:"valid_from" json:"validFrom,omitempty" gorm:"column:valid_from" bson:"validFrom,omitempty" dynamodbav:"validFrom,omitempty" firestore:"validFrom,omitempty"`
+	ValidTo    *time.Time             `yaml:"valid_to" mapstructure:"valid_to" json:"validTo,omitempty" gorm:"column:valid_to" bson:"validTo,omitempty" dynamodbav:"validTo,omitempty" firestore:"validTo,omitempty"`
+	Attributes map[string]interface{} `yaml:"attributes" mapstructure:"attributes" json:"attributes,omitempty" gorm:"-" bson:"attributes,omitempty" dynamodbav:"attributes,omitempty" firestore:"attributes,omitempty"`
+	Depth      int32                  `yaml:"-" mapstructure:"-" json:"depth,omitempty" gorm:"-" bson:"-" dynamodbav:"-" firestore:"-"`
+	Children   []Model                `yaml:"-" mapstructure:"-" json:"children,omitempty" gorm:"-" bson:"-" dynamodbav:"-" firestore:"-"`
+	Score      int32                  `yaml:"-" mapstructure:"-" json:"score,omitempty" gorm:"-" bson:"-" dynamodbav:"-" firestore:"-"`
 }
 type StructureConfig struct {
-	Master   string      `yaml:"master" mapstructure:"master" json:"master,omitempty" gorm:"column:master" bson:"master,omitempty" dynamodbav:"master,omitempty" firestore:"master,omitempty"`
-	Id       string      `yaml:"id" mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
-	Code     string      `yaml:"code" mapstructure:"code" json:"code,omitempty" gorm:"column:code" bson:"code,omitempty" dynamodbav:"code,omitempty" firestore:"code,omitempty"`
-	Text     string      `yaml:"text" mapstructure:"text" json:"text,omitempty" gorm:"column:text" bson:"text,omitempty" dynamodbav:"text,omitempty" firestore:"text,omitempty"`
-	Name     string      `yaml:"name" mapstructure:"name" json:"name,omitempty" gorm:"column:name" bson:"name,omitempty" dynamodbav:"name,omitempty" firestore:"name,omitempty"`
-	Value    string      `yaml:"value" mapstructure:"value" json:"value,omitempty" gorm:"column:value" bson:"value,omitempty" dynamodbav:"value,omitempty" firestore:"value,omitempty"`
-	Sequence string      `yaml:"sequence" mapstructure:"sequence" json:"sequence,omitempty" gorm:"column:sequence" bson:"sequence,omitempty" dynamodbav:"sequence,omitempty" firestore:"sequence,omitempty"`
-	Status   string      `yaml:"status" mapstructure:"status" json:"status,omitempty" gorm:"column:status" bson:"status,omitempty" dynamodbav:"status,omitempty" firestore:"status,omitempty"`
-	Active   interface{} `yaml:"active" mapstructure:"active" json:"active,omitempty" gorm:"column:active" bson:"active,omitempty" dynamodbav:"active,omitempty" firestore:"active,omitempty"`
-}
-type Loader interface {
-	Load(ctx context.Context, master string) ([]Model, error)
-}
-type SqlLoader struct {
-	DB     *sql.DB
-	Table  string
-	Config StructureConfig
-	Build  func(i int) string
-	Map    func(col string) string
-	colMap map[string]int
-	modelType reflect.Type
-}
-type DynamicSqlLoader struct {
+	Master     string      `yaml:"master" mapstructure:"master" json:"master,omitempty" gorm:"column:master" bson:"master,omitempty" dynamodbav:"master,omitempty" firestore:"master,omitempty"`
+	Id         string      `yaml:"id" mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
+	Code       string      `yaml:"code" mapstructure:"code" json:"code,omitempty" gorm:"column:code" bson:"code,omitempty" dynamodbav:"code,omitempty" firestore:"code,omitempty"`
+	Text       string      `yaml:"text" mapstructure:"text" json:"text,omitempty" gorm:"column:text" bson:"text,omitempty" dynamodbav:"text,omitempty" firestore:"text,omitempty"`
+	Name       string      `yaml:"name" mapstructure:"name" json:"name,omitempty" gorm:"column:name" bson:"name,omitempty" dynamodbav:"name,omitempty" firestore:"name,omitempty"`
+	Value      string      `yaml:"value" mapstructure:"value" json:"value,omitempty" gorm:"column:value" bson:"value,omitempty" dynamodbav:"value,omitempty" firestore:"value,omitempty"`
+	Sequence   string      `yaml:"sequence" mapstructure:"sequence" json:"sequence,omitempty" gorm:"column:sequence" bson:"sequence,omitempty" dynamodbav:"sequence,omitempty" firestore:"sequence,omitempty"`
+	Parent     string      `yaml:"parent" mapstructure:"parent" json:"parent,omitempty" gorm:"column:parent" bson:"parent,omitempty" dynamodbav:"parent,omitempty" firestore:"parent,omitempty"`
+	ValidFrom  string      `yaml:"valid_from" mapstructure:"valid_from" json:"validFrom,omitempty" gorm:"column:valid_from" bson:"validFrom,omitempty" dynamodbav:"validFrom,omitempty" firestore:"validFrom,omitempty"`
+	ValidTo    string      `yaml:"valid_to" mapstructure:"valid_to" json:"validTo,omitempty" gorm:"column:valid_to" bson:"validTo,omitempty" dynamodbav:"validTo,omitempty" firestore:"validTo,omitempty"`
+	Status     string      `yaml:"status" mapstructure:"status" json:"status,omitempty" gorm:"column:status" bson:"status,omitempty" dynamodbav:"status,omitempty" firestore:"status,omitempty"`
+	Active     interface{} `yaml:"active" mapstructure:"active" json:"active,omitempty" gorm:"column:active" bson:"active,omitempty" dynamodbav:"active,omitempty" firestore:"active,omitempty"`
+	Attributes []string    `yaml:"attributes" mapstructure:"attributes" json:"attributes,omitempty" gorm:"column:attributes" bson:"attributes,omitempty" dynamodbav:"attributes,omitempty" firestore:"attributes,omitempty"`
+	Unmapped   bool        `yaml:"unmapped" mapstructure:"unmapped" json:"unmapped,omitempty" gorm:"column:unmapped" bson:"unmapped,omitempty" dynamodbav:"unmapped,omitempty" firestore:"unmapped,omitempty"`
+	Locales    []string    `yaml:"locales" mapstructure:"locales" json:"locales,omitempty" gorm:"column:locales" bson:"locales,omitempty" dynamodbav:"locales,omitempty" firestore:"locales,omitempty"`
+}
+type Loader = GenericLoader[Model]
+type GenericLoader[T any] interface {
+	Load(ctx context.Context, master string) ([]T, error)
+}
+type SqlLoader = GenericSqlLoader[Model]
+type GenericSqlLoader[T any] struct {
+	DB         *sql.DB
+	Table      string
+	Config     StructureConfig
+	Build      func(i int) string
+	Map        func(col string) string
+	Dialect    Dialect
+	table      string
+	quoted     StructureConfig
+	attributes []string
+	mapped     []string
+	queries    map[string]string
+	stmts      *statements
+	colMap     map[string]int
+	modelType  reflect.Type
+	attrIndex  int
+}
+type DynamicSqlLoader = GenericDynamicSqlLoader[Model]
+type GenericDynamicSqlLoader[T any] struct {
 	DB             *sql.DB
 	Query          string
 	ParameterCount int
 	Map            func(col string) string
-	driver         string
+	Attributes     []string
+	Unmapped       bool
+	Dialect        Dialect
+	stmts          *statements
 	colMap         map[string]int
 	modelType      reflect.Type
+	attrIndex      int
 }
-type Query struct {
+type Query = GenericQuery[Model]
+type GenericQuery[T any] struct {
 	DB             *sql.DB
 	Select         string
 	Get            string
 	ParameterCount int
 	Build          func(i int) string
 	Map            func(col string) string
-	driver         string
+	Attributes     []string
+	Unmapped       bool
+	Dialect        Dialect
+	Match          string
+	IgnoreCase     bool
+	Rank           bool
+	Score          bool
+	Locales        []string
+	selects        map[likeKey]likeSelect
+	rank           rankSql
+	stmts          *statements
 	colMap         map[string]int
 	modelType      reflect.Type
+	attrIndex      int
 }
 func NewDefaultQuery(db *sql.DB, query string, getQuery string, options ...int) (*Query, error) {
 	var parameterCount int
@@ -81,73 +119,98 @@ func NewDefaultQuery(db *sql.DB, query string, getQuery string, options ...int)
 	return NewQuery(db, query, getQuery, parameterCount, true)
 }
 func NewQuery(db *sql.DB, query string, getQuery string, parameterCount int, options ...bool) (*Query, error) {
-	driver := getDriver(db)
-	var mp func(string) string
-	if driver == driverOracle {
-		mp = strings.ToUpper
-	} else {
-		mp = strings.ToLower
-	}
-	modelType := reflect.TypeOf(Model{})
+	return NewGenericQuery[Model](db, query, getQuery, parameterCount, options...)
+}
+func NewGenericQuery[T any](db *sql.DB, query string, getQuery string, parameterCount int, options ...bool) (*GenericQuery[T], error) {
+	return NewGenericQueryWithDialect[T](db, DetectDialect(db), query, getQuery, parameterCount, options...)
+}
+func NewQueryWithDialect(db *sql.DB, dialect Dialect, query string, getQuery string, parameterCount int, options ...bool) (*Query, error) {
+	return NewGenericQueryWithDialect[Model](db, dialect, query, getQuery, parameterCount, options...)
+}
+func NewGenericQueryWithDialect[T any](db *sql.DB, dialect Dialect, query string, getQuery string, parameterCount int, options ...bool) (*GenericQuery[T], error) {
+	mp := dialect.Fold
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
 	fieldsIndex, err := getColumnIndexes(modelType, mp)
 	if err != nil {
 		return nil, err
 	}
-	if parameterCount < 0 {
-		parameterCount = 1
-	}
 	var handleDriver bool
 	if len(options) >= 1 {
 		handleDriver = options[0]
 	} else {
 		handleDriver = true
 	}
-	build := getBuild(db)
-	if handleDriver {
-		if driver == driverOracle || driver == driverPostgres || driver == driverMssql {
-			var x string
-			if driver == driverOracle {
-				x = ":val"
-			} else if driver == driverPostgres {
-				x = "$"
-			} else if driver == driverMssql {
-				x = "@p"
-			}
-			for i := 0; i < parameterCount; i++ {
-				count := i + 1
-				query = strings.Replace(query, "?", x+strconv.Itoa(count), 1)
+	var ignoreCase bool
+	if len(options) >= 2 {
+		ignoreCase = options[1]
+	}
+	// the like conditions of each match mode, so that the mode can be changed per request
+	selects := make(map[likeKey]likeSelect)
+	for _, ic := range []bool{false, true} {
+		for _, match := range []string{MatchPrefix, MatchContains, MatchWordStart, MatchExact} {
+			s, escaped := rewriteLike(query, dialect, match, ic)
+			if handleDriver {
+				s, _ = RewriteQuery(s, dialect)
 			}
+			selects[likeKey{match: match, ignoreCase: ic}] = likeSelect{query: s, escaped: escaped}
 		}
 	}
-	return &Query{DB: db, Select: query, Get: getQuery, Build: build,ParameterCount: parameterCount, Map: mp, colMap: fieldsIndex, modelType: modelType}, nil
+	rewritten, count := RewriteQuery(query, dialect)
+	if handleDriver {
+		query = rewritten
+	}
+	if count > 0 {
+		parameterCount = count
+	} else if parameterCount < 0 {
+		parameterCount = 1
+	}
+	return &GenericQuery[T]{DB: db, Select: query, Get: getQuery, Build: dialect.Placeholder, ParameterCount: parameterCount, Map: mp, Dialect: dialect, Match: MatchPrefix, IgnoreCase: ignoreCase, selects: selects, rank: getRankSql(query, dialect), stmts: newStatements(db), colMap: fieldsIndex, modelType: modelType, attrIndex: getAttributesIndex(modelType)}, nil
+}
+
+type likeKey struct {
+	match      string
+	ignoreCase bool
+}
+
+// likeSelect is the query of a match mode, with the indexes of the parameters of the rewritten like conditions, which have an escape clause.
+type likeSelect struct {
+	query   string
+	escaped map[int]bool
+}
+
+func (l GenericQuery[T]) getSelect(match string) likeSelect {
+	if s, ok := l.selects[likeKey{match: match, ignoreCase: l.IgnoreCase}]; ok {
+		return s
+	}
+	return likeSelect{query: l.Select}
 }
-func (l Query) Query(ctx context.Context, key string, max int64) ([]Model, error) {
+func (l GenericQuery[T]) Query(ctx context.Context, key string, max int64) ([]T, error) {
+	return l.QueryPage(ctx, key, max, 0)
+}
+func (l GenericQuery[T]) QueryPage(ctx context.Context, key string, max int64, offset int64) ([]T, error) {
 	if max <= 0 {
 		max = 20
 	}
-	re := regexp.MustCompile(`\%|\?`)
-	key = re.ReplaceAllString(key, "")
-	models := make([]Model, 0)
-	var query string
-	if l.driver == driverOracle {
-		query = l.Select + fmt.Sprintf(" fetch next %d rows only", max)
-	} else {
-		query = l.Select + fmt.Sprintf(" limit %d", max)
+	if offset < 0 {
+		offset = 0
 	}
-
-	var rows *sql.Rows
-	var er1 error
-	pa := key + "%"
-	if l.ParameterCount > 0 {
-		params := make([]interface{}, 0)
-		for i := 1; i <= l.ParameterCount; i++ {
-			params = append(params, pa)
-		}
-		rows, er1 = l.DB.QueryContext(ctx, query, params...)
-	} else {
-		rows, er1 = l.DB.QueryContext(ctx, query)
+	models := make([]T, 0)
+	match, err := ResolveMatch(ctx, l.Match)
+	if err != nil {
+		return models, err
 	}
+	s := l.getSelect(match)
+	query := localizeSelect(ctx, s.query, l.Locales)
+	params := make([]interface{}, 0)
+	for i := 1; i <= l.ParameterCount; i++ {
+		params = append(params, buildPattern(key, match, l.Dialect, s.escaped[i]))
+	}
+	if l.Rank && l.rank.enabled {
+		query, params = l.rankQuery(query, key, params)
+	}
+	query = l.Dialect.Limit(query, max, offset)
 
+	rows, er1 := l.query(ctx, query, offset, params...)
 	if er1 != nil {
 		return models, er1
 	}
@@ -157,37 +220,50 @@ func (l Query) Query(ctx context.Context, key string, max int64) ([]Model, error
 		return models, er2
 	}
 
-	fieldsIndexSelected := make([]int, 0)
-	fieldsIndex := l.colMap
-	for _, columnsName := range columns {
-		if index, ok := fieldsIndex[columnsName]; ok {
-			fieldsIndexSelected = append(fieldsIndexSelected, index)
-		}
-	}
-	tb, er4 := scanType(rows, l.modelType, fieldsIndexSelected)
+	fieldsIndexSelected := getIndexes(columns, l.colMap, l.Attributes, l.Unmapped)
+	tb, er4 := scanType(rows, l.modelType, fieldsIndexSelected, columns, l.attrIndex)
 	if er4 != nil {
 		return models, er4
 	}
 	for _, v := range tb {
-		if c, ok := v.(*Model); ok {
+		if c, ok := v.(*T); ok {
 			models = append(models, *c)
 		}
 	}
+	if l.Rank && !l.rank.enabled {
+		// the query cannot be ranked in SQL, so only the rows of the page are ranked
+		RankModels(models, key, l.IgnoreCase)
+	}
+	if l.Score {
+		ScoreModels(models, key, l.IgnoreCase)
+	}
 	return models, nil
 }
-func (l Query) Load(ctx context.Context, key []string) ([]Model, error) {
-	models := make([]Model, 0)
+
+// query prepares the first pages only, so that the statements of the deep pages do not fill the cache.
+func (l GenericQuery[T]) query(ctx context.Context, query string, offset int64, args ...interface{}) (*sql.Rows, error) {
+	if offset > 0 {
+		return queryContext(ctx, l.DB, nil, query, args...)
+	}
+	return queryContext(ctx, l.DB, l.stmts, query, args...)
+}
+
+// Load pads the keys to a power of 2 with the last key, so that a few prepared statements serve all sizes.
+func (l GenericQuery[T]) Load(ctx context.Context, key []string) ([]T, error) {
+	models := make([]T, 0)
+	if len(key) == 0 {
+		return models, nil
+	}
 	var rows *sql.Rows
 	var er1 error
-	le := len(key)
 	args := make([]interface{}, 0)
 	params := make([]string, 0)
-	for i := 1; i <= le; i++ {
-		params = append(params, l.Build(i))
-		args = append(args, key[i - 1])
+	for i, k := range padKeys(key) {
+		params = append(params, l.Build(i+1))
+		args = append(args, k)
 	}
-	query := l.Get + fmt.Sprintf(" (%s)", strings.Join(params, ","))
-	rows, er1 = l.DB.QueryContext(ctx, query, args...)
+	query := localizeSelect(ctx, l.Get, l.Locales) + fmt.Sprintf(" (%s)", strings.Join(params, ","))
+	rows, er1 = queryContext(ctx, l.DB, l.stmts, query, args...)
 	if er1 != nil {
 		return models, er1
 	}
@@ -197,19 +273,13 @@ func (l Query) Load(ctx context.Context, key []string) ([]Model, error) {
 		return models, er2
 	}
 
-	fieldsIndexSelected := make([]int, 0)
-	fieldsIndex := l.colMap
-	for _, columnsName := range columns {
-		if index, ok := fieldsIndex[columnsName]; ok {
-			fieldsIndexSelected = append(fieldsIndexSelected, index)
-		}
-	}
-	tb, er4 := scanType(rows, l.modelType, fieldsIndexSelected)
+	fieldsIndexSelected := getIndexes(columns, l.colMap, l.Attributes, l.Unmapped)
+	tb, er4 := scanType(rows, l.modelType, fieldsIndexSelected, columns, l.attrIndex)
 	if er4 != nil {
 		return models, er4
 	}
 	for _, v := range tb {
-		if c, ok := v.(*Model); ok {
+		if c, ok := v.(*T); ok {
 			models = append(models, *c)
 		}
 	}
@@ -225,47 +295,40 @@ func NewDefaultDynamicSqlCodeLoader(db *sql.DB, query string, options ...int) (*
 	return NewDynamicSqlCodeLoader(db, query, parameterCount, true)
 }
 func NewDynamicSqlCodeLoader(db *sql.DB, query string, parameterCount int, options ...bool) (*DynamicSqlLoader, error) {
-	driver := getDriver(db)
-	var mp func(string) string
-	if driver == driverOracle {
-		mp = strings.ToUpper
-	} else {
-		mp = strings.ToLower
-	}
-	modelType := reflect.TypeOf(Model{})
+	return NewGenericDynamicSqlCodeLoader[Model](db, query, parameterCount, options...)
+}
+func NewGenericDynamicSqlCodeLoader[T any](db *sql.DB, query string, parameterCount int, options ...bool) (*GenericDynamicSqlLoader[T], error) {
+	return NewGenericDynamicSqlCodeLoaderWithDialect[T](db, DetectDialect(db), query, parameterCount, options...)
+}
+func NewDynamicSqlCodeLoaderWithDialect(db *sql.DB, dialect Dialect, query string, parameterCount int, options ...bool) (*DynamicSqlLoader, error) {
+	return NewGenericDynamicSqlCodeLoaderWithDialect[Model](db, dialect, query, parameterCount, options...)
+}
+func NewGenericDynamicSqlCodeLoaderWithDialect[T any](db *sql.DB, dialect Dialect, query string, parameterCount int, options ...bool) (*GenericDynamicSqlLoader[T], error) {
+	mp := dialect.Fold
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
 	fieldsIndex, err := getColumnIndexes(modelType, mp)
 	if err != nil {
 		return nil, err
 	}
-	if parameterCount < 0 {
-		parameterCount = 1
-	}
 	var handleDriver bool
 	if len(options) >= 1 {
 		handleDriver = options[0]
 	} else {
 		handleDriver = true
 	}
+	rewritten, count := RewriteQuery(query, dialect)
 	if handleDriver {
-		if driver == driverOracle || driver == driverPostgres || driver == driverMssql {
-			var x string
-			if driver == driverOracle {
-				x = ":val"
-			} else if driver == driverPostgres {
-				x = "$"
-			} else if driver == driverMssql {
-				x = "@p"
-			}
-			for i := 0; i < parameterCount; i++ {
-				count := i + 1
-				query = strings.Replace(query, "?", x+strconv.Itoa(count), 1)
-			}
-		}
+		query = rewritten
+	}
+	if count > 0 {
+		parameterCount = count
+	} else if parameterCount < 0 {
+		parameterCount = 1
 	}
-	return &DynamicSqlLoader{DB: db, Query: query, ParameterCount: parameterCount, Map: mp, colMap: fieldsIndex, modelType: modelType}, nil
+	return &GenericDynamicSqlLoader[T]{DB: db, Query: query, ParameterCount: parameterCount, Map: mp, Dialect: dialect, stmts: newStatements(db), colMap: fieldsIndex, modelType: modelType, attrIndex: getAttributesIndex(modelType)}, nil
 }
-func (l DynamicSqlLoader) Load(ctx context.Context, master string) ([]Model, error) {
-	models := make([]Model, 0)
+func (l GenericDynamicSqlLoader[T]) Load(ctx context.Context, master string) ([]T, error) {
+	models := make([]T, 0)
 
 	var rows *sql.Rows
 	var er1 error
@@ -274,9 +337,9 @@ func (l DynamicSqlLoader) Load(ctx context.Context, master string) ([]Model, err
 		for i := 1; i <= l.ParameterCount; i++ {
 			params = append(params, master)
 		}
-		rows, er1 = l.DB.QueryContext(ctx, l.Query, params...)
+		rows, er1 = queryContext(ctx, l.DB, l.stmts, l.Query, params...)
 	} else {
-		rows, er1 = l.DB.QueryContext(ctx, l.Query)
+		rows, er1 = queryContext(ctx, l.DB, l.stmts, l.Query)
 	}
 
 	if er1 != nil {
@@ -288,152 +351,508 @@ func (l DynamicSqlLoader) Load(ctx context.Context, master string) ([]Model, err
 		return models, er2
 	}
 
-	fieldsIndexSelected := make([]int, 0)
-	fieldsIndex := l.colMap
-	for _, columnsName := range columns {
-		if index, ok := fieldsIndex[columnsName]; ok {
-			fieldsIndexSelected = append(fieldsIndexSelected, index)
-		}
-	}
-	tb, er4 := scanType(rows, l.modelType, fieldsIndexSelected)
+	fieldsIndexSelected := getIndexes(columns, l.colMap, l.Attributes, l.Unmapped)
+	tb, er4 := scanType(rows, l.modelType, fieldsIndexSelected, columns, l.attrIndex)
 	if er4 != nil {
 		return models, er4
 	}
 	for _, v := range tb {
-		if c, ok := v.(*Model); ok {
+		if c, ok := v.(*T); ok {
 			models = append(models, *c)
 		}
 	}
 	return models, nil
 }
 func NewSqlCodeLoader(db *sql.DB, table string, config StructureConfig, options ...func(i int) string) (*SqlLoader, error) {
-	var build func(i int) string
+	return NewGenericSqlCodeLoader[Model](db, table, config, options...)
+}
+func NewGenericSqlCodeLoader[T any](db *sql.DB, table string, config StructureConfig, options ...func(i int) string) (*GenericSqlLoader[T], error) {
+	l, err := NewGenericSqlCodeLoaderWithDialect[T](db, DetectDialect(db), table, config)
+	if err != nil {
+		return nil, err
+	}
 	if len(options) > 0 && options[0] != nil {
-		build = options[0]
-	} else {
-		build = getBuild(db)
+		l.Build = options[0]
+		l.queries = l.buildQueries()
+	}
+	return l, nil
+}
+func NewSqlCodeLoaderWithDialect(db *sql.DB, dialect Dialect, table string, config StructureConfig) (*SqlLoader, error) {
+	return NewGenericSqlCodeLoaderWithDialect[Model](db, dialect, table, config)
+}
+func NewGenericSqlCodeLoaderWithDialect[T any](db *sql.DB, dialect Dialect, table string, config StructureConfig) (*GenericSqlLoader[T], error) {
+	quotedTable, err := QuoteIdentifier(dialect, table)
+	if err != nil {
+		return nil, fmt.Errorf("invalid table: %s", err.Error())
+	}
+	quoted, err := quoteConfig(dialect, config)
+	if err != nil {
+		return nil, err
 	}
-	driver := getDriver(db)
-	var mp func(string) string
-	if driver == driverOracle {
-		mp = strings.ToUpper
+	attributes := make([]string, 0)
+	for _, attribute := range config.Attributes {
+		attributes = append(attributes, columnName(attribute))
 	}
-	modelType := reflect.TypeOf(Model{})
+	mp := dialect.Fold
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
 	fieldsIndex, err := getColumnIndexes(modelType, mp)
 	if err != nil {
 		return nil, err
 	}
-	return &SqlLoader{DB: db, Table: table, Config: config, Build: build, Map: mp, colMap: fieldsIndex, modelType: modelType}, nil
+	l := &GenericSqlLoader[T]{DB: db, Table: table, Config: config, Build: dialect.Placeholder, Map: mp, Dialect: dialect, table: quotedTable, quoted: quoted, attributes: attributes, mapped: getMappedColumns(config), stmts: newStatements(db), colMap: fieldsIndex, modelType: modelType, attrIndex: getAttributesIndex(modelType)}
+	l.queries = l.buildQueries()
+	return l, nil
 }
-func (l SqlLoader) Load(ctx context.Context, master string) ([]Model, error) {
-	models := make([]Model, 0)
-	s := make([]string, 0)
+func (l GenericSqlLoader[T]) Load(ctx context.Context, master string) ([]T, error) {
 	values := make([]interface{}, 0)
-	sql2 := ""
-
-	c := l.Config
-	if len(c.Id) > 0 {
-		sf := fmt.Sprintf("%s as id", c.Id)
-		s = append(s, sf)
+	c := l.quoted
+	name := getLocaleColumn(ctx, c.Name, c.Locales)
+	query, ok := l.queries[name]
+	if !ok {
+		query = l.buildQuery(name)
 	}
-	if len(c.Code) > 0 {
-		sf := fmt.Sprintf("%s as code", c.Code)
-		s = append(s, sf)
+	if len(c.Master) > 0 {
+		values = append(values, master)
 	}
-	if len(c.Name) > 0 {
-		sf := fmt.Sprintf("%s as name", c.Name)
-		s = append(s, sf)
+	if len(c.Status) > 0 && c.Active != nil {
+		values = append(values, c.Active)
 	}
-	if len(c.Value) > 0 {
-		sf := fmt.Sprintf("%s as value", c.Value)
-		s = append(s, sf)
+	asOf, _ := GetAsOf(ctx)
+	if len(c.ValidFrom) > 0 {
+		values = append(values, asOf)
 	}
-	if len(c.Text) > 0 {
-		sf := fmt.Sprintf("%s as text", c.Text)
-		s = append(s, sf)
+	if len(c.ValidTo) > 0 {
+		values = append(values, asOf)
+	}
+	models, err := l.loadCodes(ctx, query, values)
+	if err != nil {
+		return nil, err
+	}
+	if hasBoundary(ctx) {
+		for _, column := range []string{c.ValidFrom, c.ValidTo} {
+			if len(column) == 0 {
+				continue
+			}
+			boundary, er4 := l.nextBoundary(ctx, master, column, asOf)
+			if er4 != nil {
+				return nil, er4
+			}
+			setBoundary(ctx, boundary)
+		}
+	}
+	return models, nil
+}
+func (l GenericSqlLoader[T]) loadCodes(ctx context.Context, query string, values []interface{}) ([]T, error) {
+	models := make([]T, 0)
+	rows, err1 := queryContext(ctx, l.DB, l.stmts, query, values...)
+	if err1 != nil {
+		return nil, err1
+	}
+	defer rows.Close()
+	columns, er1 := rows.Columns()
+	if er1 != nil {
+		return nil, er1
+	}
+	// get list indexes column
+	fieldsIndexSelected := l.getIndexes(columns)
+	tb, er3 := scanType(rows, l.modelType, fieldsIndexSelected, columns, l.attrIndex)
+	if er3 != nil {
+		return nil, er3
 	}
+	for _, v := range tb {
+		if c, ok := v.(*T); ok {
+			models = append(models, *c)
+		}
+	}
+	return models, nil
+}
+
+// buildQueries builds the select statements once, for the name column and for the column of each locale.
+func (l GenericSqlLoader[T]) buildQueries() map[string]string {
+	c := l.quoted
+	queries := make(map[string]string)
+	queries[c.Name] = l.buildQuery(c.Name)
+	for _, locale := range c.Locales {
+		name := localeColumn(c.Name, locale, c.Locales)
+		queries[name] = l.buildQuery(name)
+	}
+	return queries
+}
+func (l GenericSqlLoader[T]) buildQuery(name string) string {
+	c := l.quoted
 	osequence := ""
 	if len(c.Sequence) > 0 {
 		osequence = fmt.Sprintf("order by %s", c.Sequence)
 	}
-	p1 := ""
+	where := make([]string, 0)
 	i := 1
 	if len(c.Master) > 0 {
-		p1 = fmt.Sprintf("%s = %s", c.Master, l.Build(i))
+		where = append(where, fmt.Sprintf("%s = %s", c.Master, l.Build(i)))
+		i = i + 1
+	}
+	if len(c.Status) > 0 && c.Active != nil {
+		where = append(where, fmt.Sprintf("%s = %s", c.Status, l.Build(i)))
 		i = i + 1
+	}
+	if len(c.ValidFrom) > 0 {
+		where = append(where, fmt.Sprintf("(%s is null or %s <= %s)", c.ValidFrom, c.ValidFrom, l.Build(i)))
+		i = i + 1
+	}
+	if len(c.ValidTo) > 0 {
+		where = append(where, fmt.Sprintf("(%s is null or %s > %s)", c.ValidTo, c.ValidTo, l.Build(i)))
+	}
+	cols := strings.Join(l.getColumns(name), ",")
+	if cols == "" {
+		cols = "*"
+	}
+	if len(where) > 0 {
+		return fmt.Sprintf("select %s from %s where %s %s", cols, l.table, strings.Join(where, " and "), osequence)
+	}
+	return fmt.Sprintf("select %s from %s %s", cols, l.table, osequence)
+}
+
+// Close closes the prepared statements. The loader cannot be used after Close.
+func (l GenericSqlLoader[T]) Close() error {
+	return l.stmts.Close()
+}
+func (l GenericDynamicSqlLoader[T]) Close() error {
+	return l.stmts.Close()
+}
+func (l GenericQuery[T]) Close() error {
+	return l.stmts.Close()
+}
+
+// nextBoundary returns the earliest value of the column after the reference time, so that the caches expire when a code becomes valid or invalid.
+func (l GenericSqlLoader[T]) nextBoundary(ctx context.Context, master string, column string, asOf time.Time) (time.Time, error) {
+	var boundary time.Time
+	c := l.quoted
+	values := make([]interface{}, 0)
+	where := make([]string, 0)
+	if len(c.Master) > 0 {
 		values = append(values, master)
+		where = append(where, fmt.Sprintf("%s = %s", c.Master, l.Build(len(values))))
+	}
+	// the inactive codes are not loaded, so they do not change the effective codes
+	if len(c.Status) > 0 && c.Active != nil {
+		values = append(values, c.Active)
+		where = append(where, fmt.Sprintf("%s = %s", c.Status, l.Build(len(values))))
+	}
+	values = append(values, asOf)
+	where = append(where, fmt.Sprintf("%s > %s", column, l.Build(len(values))))
+	query := l.Dialect.Limit(fmt.Sprintf("select %s from %s where %s order by %s", column, l.table, strings.Join(where, " and "), column), 1, 0)
+	rows, er1 := queryContext(ctx, l.DB, l.stmts, query, values...)
+	if er1 != nil {
+		return boundary, er1
+	}
+	defer rows.Close()
+	if rows.Next() {
+		var t sql.NullTime
+		if er2 := rows.Scan(&t); er2 != nil {
+			return boundary, &ScanError{Row: 1, Err: er2}
+		}
+		boundary = t.Time
 	}
-	cols := strings.Join(s, ",")
+	return boundary, ClassifyError(rows.Err())
+}
+
+// LoadAll returns the codes of all masters. The codes of all validity windows are returned, to be filtered by the reference time of each request.
+func (l GenericSqlLoader[T]) LoadAll(ctx context.Context) (map[string][]T, error) {
+	c := l.quoted
+	where := make([]string, 0)
+	values := make([]interface{}, 0)
 	if len(c.Status) > 0 && c.Active != nil {
-		p2 := fmt.Sprintf("%s = %s", c.Status, l.Build(i))
+		where = append(where, fmt.Sprintf("%s = %s", c.Status, l.Build(1)))
 		values = append(values, c.Active)
+	}
+	if len(c.Master) == 0 {
+		// the codes without master are loaded with the master "", as Load does not filter them by master
+		cols := strings.Join(l.getColumns(getLocaleColumn(ctx, c.Name, c.Locales)), ",")
 		if cols == "" {
 			cols = "*"
 		}
-		if len(p1) > 0 {
-			sql2 = fmt.Sprintf("select %s from %s where %s and %s %s", cols, l.Table, p1, p2, osequence)
-		} else {
-			sql2 = fmt.Sprintf("select %s from %s where %s %s", cols, l.Table, p2, osequence)
+		query := fmt.Sprintf("select %s from %s", cols, l.table)
+		if len(where) > 0 {
+			query = query + " where " + strings.Join(where, " and ")
 		}
-	} else {
-		if cols == "" {
-			cols = "*"
+		if len(c.Sequence) > 0 {
+			query = query + " order by " + c.Sequence
 		}
-		if len(p1) > 0 {
-			sql2 = fmt.Sprintf("select %s from %s where %s %s", cols, l.Table, p1, osequence)
-		} else {
-			sql2 = fmt.Sprintf("select %s from %s %s", cols, l.Table, osequence)
+		models, err := l.loadCodes(ctx, query, values)
+		if err != nil {
+			return nil, err
 		}
+		return map[string][]T{"": models}, nil
 	}
-	if len(sql2) > 0 {
-		rows, err1 := l.DB.QueryContext(ctx, sql2, values...)
-		if err1 != nil {
-			return nil, err1
+	return l.loadMasters(ctx, l.getColumns(getLocaleColumn(ctx, c.Name, c.Locales)), where, values)
+}
+
+// LoadMany returns the effective codes of the masters in one query.
+func (l GenericSqlLoader[T]) LoadMany(ctx context.Context, masters []string) (map[string][]T, error) {
+	c := l.quoted
+	codes := make(map[string][]T)
+	if len(masters) == 0 {
+		return codes, nil
+	}
+	if len(c.Master) == 0 {
+		models, err := l.Load(ctx, "")
+		if err != nil {
+			return nil, err
 		}
-		defer rows.Close()
-		columns, er1 := rows.Columns()
-		if er1 != nil {
-			return nil, er1
+		for _, master := range masters {
+			codes[master] = models
 		}
-		fieldsIndexSelected := make([]int, 0)
-		// get list indexes column
-		fieldsIndex := l.colMap
-		for _, columnsName := range columns {
-			if index, ok := fieldsIndex[columnsName]; ok {
-				fieldsIndexSelected = append(fieldsIndexSelected, index)
+		return codes, nil
+	}
+	where := make([]string, 0)
+	values := make([]interface{}, 0)
+	params := make([]string, 0)
+	for _, master := range padKeys(masters) {
+		values = append(values, master)
+		params = append(params, l.Build(len(values)))
+	}
+	where = append(where, fmt.Sprintf("%s in (%s)", c.Master, strings.Join(params, ",")))
+	if len(c.Status) > 0 && c.Active != nil {
+		values = append(values, c.Active)
+		where = append(where, fmt.Sprintf("%s = %s", c.Status, l.Build(len(values))))
+	}
+	asOf, _ := GetAsOf(ctx)
+	if len(c.ValidFrom) > 0 {
+		values = append(values, asOf)
+		where = append(where, fmt.Sprintf("(%s is null or %s <= %s)", c.ValidFrom, c.ValidFrom, l.Build(len(values))))
+	}
+	if len(c.ValidTo) > 0 {
+		values = append(values, asOf)
+		where = append(where, fmt.Sprintf("(%s is null or %s > %s)", c.ValidTo, c.ValidTo, l.Build(len(values))))
+	}
+	loaded, err := l.loadMasters(ctx, l.getColumns(getLocaleColumn(ctx, c.Name, c.Locales)), where, values)
+	if err != nil {
+		return nil, err
+	}
+	for _, master := range masters {
+		if models, ok := loaded[master]; ok {
+			codes[master] = models
+		} else {
+			codes[master] = make([]T, 0)
+		}
+	}
+	if hasBoundary(ctx) {
+		for _, master := range masters {
+			for _, column := range []string{c.ValidFrom, c.ValidTo} {
+				if len(column) == 0 {
+					continue
+				}
+				boundary, er1 := l.nextBoundary(ctx, master, column, asOf)
+				if er1 != nil {
+					return nil, er1
+				}
+				setBoundary(ctx, boundary)
 			}
 		}
-		tb, er3 := scanType(rows, l.modelType, fieldsIndexSelected)
-		if er3 != nil {
-			return nil, er3
+	}
+	return codes, nil
+}
+func (l GenericSqlLoader[T]) loadMasters(ctx context.Context, columns []string, where []string, values []interface{}) (map[string][]T, error) {
+	c := l.quoted
+	s := []string{fmt.Sprintf("%s as master", c.Master)}
+	s = append(s, columns...)
+	if len(s) == 1 {
+		s = append(s, "*")
+	}
+	w := ""
+	if len(where) > 0 {
+		w = " where " + strings.Join(where, " and ")
+	}
+	order := fmt.Sprintf(" order by %s", c.Master)
+	if len(c.Sequence) > 0 {
+		order = order + ", " + c.Sequence
+	}
+	query := fmt.Sprintf("select %s from %s%s%s", strings.Join(s, ","), l.table, w, order)
+	rows, er1 := queryContext(ctx, l.DB, l.stmts, query, values...)
+	if er1 != nil {
+		return nil, er1
+	}
+	defer rows.Close()
+	cols, er2 := rows.Columns()
+	if er2 != nil {
+		return nil, er2
+	}
+	indexes := l.getIndexes(cols[1:])
+	codes := make(map[string][]T)
+	row := 0
+	for rows.Next() {
+		var master string
+		var model T
+		row++
+		r := structScan(&model, indexes)
+		if er3 := rows.Scan(append([]interface{}{&master}, r...)...); er3 != nil {
+			return nil, &ScanError{Row: row, Err: er3}
 		}
-		for _, v := range tb {
-			if c, ok := v.(*Model); ok {
-				models = append(models, *c)
+		setAttributes(&model, indexes, cols[1:], r, l.attrIndex)
+		codes[master] = append(codes[master], model)
+	}
+	if er4 := rows.Err(); er4 != nil {
+		return nil, ClassifyError(er4)
+	}
+	return codes, nil
+}
+
+// getColumns returns the configured columns, then all the columns of the table for the unmapped attributes.
+func (l GenericSqlLoader[T]) getColumns(name string) []string {
+	cols := getColumns(l.quoted, name)
+	if l.Config.Unmapped && len(cols) > 0 {
+		cols = append(cols, l.table+".*")
+	}
+	return cols
+}
+
+// getIndexes maps the configured columns to the fields. The columns of the table which are not configured are the unmapped attributes.
+func (l GenericSqlLoader[T]) getIndexes(columns []string) []int {
+	configured := len(getColumns(l.quoted, l.quoted.Name))
+	if !l.Config.Unmapped || configured == 0 || configured > len(columns) {
+		return getIndexes(columns, l.colMap, l.attributes, l.Config.Unmapped)
+	}
+	indexes := getIndexes(columns[:configured], l.colMap, l.attributes, false)
+	for _, column := range columns[configured:] {
+		if containsFold(l.mapped, column) || containsFold(l.attributes, column) {
+			indexes = append(indexes, ignoredColumn)
+		} else {
+			indexes = append(indexes, attributeColumn)
+		}
+	}
+	return indexes
+}
+func getMappedColumns(c StructureConfig) []string {
+	mapped := make([]string, 0)
+	for _, column := range []string{c.Master, c.Id, c.Code, c.Name, c.Value, c.Text, c.Parent, c.ValidFrom, c.ValidTo, c.Status} {
+		if len(column) > 0 {
+			mapped = append(mapped, columnName(column))
+		}
+	}
+	for _, locale := range c.Locales {
+		mapped = append(mapped, localeColumnName(c.Name, locale))
+	}
+	if len(c.Sequence) > 0 {
+		for _, item := range strings.Split(c.Sequence, ",") {
+			if fields := strings.Fields(item); len(fields) > 0 {
+				mapped = append(mapped, columnName(fields[0]))
 			}
 		}
 	}
-	return models, nil
+	return mapped
+}
+func getColumns(c StructureConfig, name string) []string {
+	s := make([]string, 0)
+	if len(c.Id) > 0 {
+		s = append(s, fmt.Sprintf("%s as id", c.Id))
+	}
+	if len(c.Code) > 0 {
+		s = append(s, fmt.Sprintf("%s as code", c.Code))
+	}
+	if len(c.Name) > 0 {
+		s = append(s, fmt.Sprintf("%s as name", name))
+	}
+	if len(c.Value) > 0 {
+		s = append(s, fmt.Sprintf("%s as value", c.Value))
+	}
+	if len(c.Text) > 0 {
+		s = append(s, fmt.Sprintf("%s as text", c.Text))
+	}
+	if len(c.Parent) > 0 {
+		s = append(s, fmt.Sprintf("%s as parent", c.Parent))
+	}
+	if len(c.ValidFrom) > 0 {
+		s = append(s, fmt.Sprintf("%s as valid_from", c.ValidFrom))
+	}
+	if len(c.ValidTo) > 0 {
+		s = append(s, fmt.Sprintf("%s as valid_to", c.ValidTo))
+	}
+	return append(s, c.Attributes...)
 }
 
-func scanType(rows *sql.Rows, modelType reflect.Type, indexes []int) (t []interface{}, err error) {
+func scanType(rows *sql.Rows, modelType reflect.Type, indexes []int, columns []string, attrIndex int) (t []interface{}, err error) {
 	for rows.Next() {
 		initModel := reflect.New(modelType).Interface()
-		if err = rows.Scan(structScan(initModel, indexes)...); err == nil {
-			t = append(t, initModel)
+		r := structScan(initModel, indexes)
+		if err = rows.Scan(r...); err != nil {
+			return nil, &ScanError{Row: len(t) + 1, Err: err}
 		}
+		setAttributes(initModel, indexes, columns, r, attrIndex)
+		t = append(t, initModel)
 	}
-	return
+	return t, ClassifyError(rows.Err())
 }
 func structScan(s interface{}, indexColumns []int) (r []interface{}) {
 	if s != nil {
 		maps := reflect.Indirect(reflect.ValueOf(s))
 		for _, index := range indexColumns {
-			r = append(r, maps.Field(index).Addr().Interface())
+			if index >= 0 {
+				r = append(r, maps.Field(index).Addr().Interface())
+			} else {
+				r = append(r, new(interface{}))
+			}
 		}
 	}
 	return
 }
+
+const (
+	attributeColumn = -1
+	ignoredColumn   = -2
+)
+
+// getIndexes returns the field index of each column, attributeColumn for the extra columns, or ignoredColumn.
+func getIndexes(columns []string, fieldsIndex map[string]int, attributes []string, unmapped bool) []int {
+	indexes := make([]int, 0)
+	for _, columnsName := range columns {
+		if index, ok := fieldsIndex[columnsName]; ok {
+			indexes = append(indexes, index)
+		} else if unmapped || containsFold(attributes, columnsName) {
+			indexes = append(indexes, attributeColumn)
+		} else {
+			indexes = append(indexes, ignoredColumn)
+		}
+	}
+	return indexes
+}
+func setAttributes(s interface{}, indexes []int, columns []string, r []interface{}, attrIndex int) {
+	if attrIndex < 0 {
+		return
+	}
+	var attributes map[string]interface{}
+	for i, index := range indexes {
+		if index != attributeColumn {
+			continue
+		}
+		v := *(r[i].(*interface{}))
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		if attributes == nil {
+			attributes = make(map[string]interface{})
+		}
+		attributes[strings.ToLower(columns[i])] = v
+	}
+	if attributes != nil {
+		reflect.Indirect(reflect.ValueOf(s)).Field(attrIndex).Set(reflect.ValueOf(attributes))
+	}
+}
+func getAttributesIndex(modelType reflect.Type) int {
+	if modelType.Kind() != reflect.Struct {
+		return -1
+	}
+	if field, ok := modelType.FieldByName("Attributes"); ok && len(field.Index) == 1 && field.Type == reflect.TypeOf(map[string]interface{}{}) {
+		return field.Index[0]
+	}
+	return -1
+}
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
 func getColumnIndexes(modelType reflect.Type, mp func(col string) string) (map[string]int, error) {
 	mapp := make(map[string]int, 0)
 	if modelType.Kind() != reflect.Struct {
@@ -467,49 +886,3 @@ func findTag(tag string, key string) (string, bool) {
 	}
 	return "", false
 }
-
-func buildParam(i int) string {
-	return "?"
-}
-func buildOracleParam(i int) string {
-	return ":val" + strconv.Itoa(i)
-}
-func buildMsSqlParam(i int) string {
-	return "@p" + strconv.Itoa(i)
-}
-func buildDollarParam(i int) string {
-	return "$" + strconv.Itoa(i)
-}
-func getBuild(db *sql.DB) func(i int) string {
-	driver := reflect.TypeOf(db.Driver()).String()
-	switch driver {
-	case "*pq.Driver":
-		return buildDollarParam
-	case "*godror.drv":
-		return buildOracleParam
-	case "*mssql.Driver":
-		return buildMsSqlParam
-	default:
-		return buildParam
-	}
-}
-func getDriver(db *sql.DB) string {
-	if db == nil {
-		return driverNotSupport
-	}
-	driver := reflect.TypeOf(db.Driver()).String()
-	switch driver {
-	case "*pq.Driver":
-		return driverPostgres
-	case "*godror.drv":
-		return driverOracle
-	case "*mysql.MySQLDriver":
-		return driverMysql
-	case "*mssql.Driver":
-		return driverMssql
-	case "*sqlite3.SQLiteDriver":
-		return driverSqlite3
-	default:
-		return driverNotSupport
-	}
-}
@@ -0,0 +1,179 @@
+package code
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const bom = "\xEF\xBB\xBF"
+
+type RowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if len(e.Column) > 0 {
+		return fmt.Sprintf("line %d, column %s: %s", e.Line, e.Column, e.Err.Error())
+	}
+	return fmt.Sprintf("line %d: %s", e.Line, e.Err.Error())
+}
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+type RowErrors []*RowError
+
+func (e RowErrors) Error() string {
+	s := make([]string, 0)
+	for _, err := range e {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "; ")
+}
+
+type CsvLoader struct {
+	Config    StructureConfig
+	Comma     rune
+	codes     map[string][]Model
+	hasMaster bool
+}
+
+func NewCsvFileLoader(fsys fs.FS, path string, config StructureConfig, options ...rune) (*CsvLoader, error) {
+	f, err := fsys.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return NewCsvLoader(f, config, options...)
+}
+func NewCsvLoader(reader io.Reader, config StructureConfig, options ...rune) (*CsvLoader, error) {
+	comma := ','
+	if len(options) > 0 && options[0] != 0 {
+		comma = options[0]
+	}
+	codes, hasMaster, err := readCsv(reader, config, comma)
+	if err != nil {
+		return nil, err
+	}
+	return &CsvLoader{Config: config, Comma: comma, codes: codes, hasMaster: hasMaster}, nil
+}
+func (l CsvLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	if !l.hasMaster {
+		// without the master column, the codes are of all masters
+		master = ""
+	}
+	codes, ok := l.codes[master]
+	if !ok {
+		return nil, &MasterError{Master: master}
+	}
+	return copyModels(codes), nil
+}
+
+// readCsv returns the codes by master, and whether the csv has a master column, configured or named "master".
+func readCsv(reader io.Reader, c StructureConfig, comma rune) (map[string][]Model, bool, error) {
+	br := bufio.NewReader(reader)
+	if b, err := br.Peek(len(bom)); err == nil && string(b) == bom {
+		br.Discard(len(bom))
+	}
+	r := csv.NewReader(br)
+	r.Comma = comma
+	header, err := r.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, false, errors.New("csv has no header")
+		}
+		return nil, false, err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.TrimSpace(name)] = i
+	}
+	keys := []string{"master", "id", "code", "name", "value", "text", "parent", "sequence", "status"}
+	configured := []string{c.Master, c.Id, c.Code, c.Name, c.Value, c.Text, c.Parent, c.Sequence, c.Status}
+	indexes := make(map[string]int)
+	for i, key := range keys {
+		if len(configured[i]) > 0 {
+			index, ok := columns[configured[i]]
+			if !ok {
+				return nil, false, fmt.Errorf("column '%s' of config '%s' is not in the csv header", configured[i], key)
+			}
+			indexes[key] = index
+		} else if index, ok := columns[key]; ok && key != "status" {
+			indexes[key] = index
+		}
+	}
+	var active string
+	checkStatus := len(c.Status) > 0 && c.Active != nil
+	if checkStatus {
+		active = fmt.Sprint(c.Active)
+	}
+	codes := make(map[string][]Model)
+	errs := make(RowErrors, 0)
+	for {
+		record, er1 := r.Read()
+		if er1 == io.EOF {
+			break
+		}
+		if er1 != nil {
+			var pe *csv.ParseError
+			if errors.As(er1, &pe) {
+				errs = append(errs, &RowError{Line: pe.Line, Err: pe.Err})
+				if pe.Err == csv.ErrFieldCount {
+					continue
+				}
+				break
+			}
+			return nil, false, er1
+		}
+		line, _ := r.FieldPos(0)
+		get := func(key string) string {
+			if index, ok := indexes[key]; ok && index < len(record) {
+				return record[index]
+			}
+			return ""
+		}
+		master := get("master")
+		if checkStatus && get("status") != active {
+			// the master is known, even if it has no active codes
+			if _, ok := codes[master]; !ok {
+				codes[master] = make([]Model, 0)
+			}
+			continue
+		}
+		m := Model{Id: get("id"), Code: get("code"), Name: get("name"), Value: get("value"), Text: get("text"), Parent: get("parent")}
+		if s := strings.TrimSpace(get("sequence")); len(s) > 0 {
+			sequence, er2 := strconv.ParseInt(s, 10, 32)
+			if er2 != nil {
+				errs = append(errs, &RowError{Line: line, Column: header[indexes["sequence"]], Err: er2})
+				continue
+			}
+			m.Sequence = int32(sequence)
+		}
+		codes[master] = append(codes[master], m)
+	}
+	if len(errs) > 0 {
+		return nil, false, errs
+	}
+	_, hasMaster := indexes["master"]
+	if _, ok := codes[""]; !ok && !hasMaster {
+		codes[""] = make([]Model, 0)
+	}
+	if len(c.Sequence) > 0 {
+		for _, models := range codes {
+			sort.SliceStable(models, func(i, j int) bool {
+				return models[i].Sequence < models[j].Sequence
+			})
+		}
+	}
+	return codes, hasMaster, nil
+}
@@ -0,0 +1,82 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCsvLoader(t *testing.T) {
+	data := bom + "type,key,label,seq,state\ngender,M,Male,2,A\ngender,F,Female,1,A\ngender,X,Other,3,I\ntitle,MR,Mr,1,A\n"
+	l, err := NewCsvLoader(strings.NewReader(data), StructureConfig{Master: "type", Code: "key", Name: "label", Sequence: "seq", Status: "state", Active: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := l.Load(context.Background(), "gender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(models); !equalStrings(codes, []string{"F", "M"}) {
+		t.Errorf("Load = %v, want the active codes by sequence", codes)
+	}
+	if models[0].Name != "Female" || models[0].Sequence != 1 {
+		t.Errorf("Load = %+v, want the mapped columns", models[0])
+	}
+}
+
+func TestCsvLoaderRowErrors(t *testing.T) {
+	data := "code,name,sequence\nA,Alpha,1\nB,Beta,x\nC\nD,Delta,4\n"
+	_, err := NewCsvLoader(strings.NewReader(data), StructureConfig{Code: "code", Name: "name", Sequence: "sequence"})
+	var errs RowErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("NewCsvLoader error = %v, want RowErrors", err)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("NewCsvLoader errors = %v, want 2 errors", errs)
+	}
+	if errs[0].Line != 3 || errs[0].Column != "sequence" {
+		t.Errorf("first error = %v, want line 3, column sequence", errs[0])
+	}
+	if errs[1].Line != 4 {
+		t.Errorf("second error = %v, want line 4", errs[1])
+	}
+}
+
+func TestCsvLoaderHeader(t *testing.T) {
+	_, err := NewCsvLoader(strings.NewReader("code,name\nA,Alpha\n"), StructureConfig{Code: "id", Name: "name"})
+	if err == nil || !strings.Contains(err.Error(), "'id'") {
+		t.Errorf("NewCsvLoader error = %v, want the missing column", err)
+	}
+	_, err = NewCsvLoader(strings.NewReader(""), StructureConfig{})
+	if err == nil {
+		t.Error("NewCsvLoader of an empty csv, want an error")
+	}
+}
+
+func TestCsvLoaderMaster(t *testing.T) {
+	data := "master,code,name,status\ngender,F,Female,A\ntitle,MR,Mr,I\n"
+	l, err := NewCsvLoader(strings.NewReader(data), StructureConfig{Code: "code", Name: "name", Status: "status", Active: "A"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := l.Load(context.Background(), "gender")
+	if err != nil || len(models) != 1 {
+		t.Errorf("Load = %v, %v, want the codes of the master column of the header", models, err)
+	}
+	models, err = l.Load(context.Background(), "title")
+	if err != nil || len(models) != 0 {
+		t.Errorf("Load of a master without active codes = %v, %v, want no codes", models, err)
+	}
+	if _, err = l.Load(context.Background(), "status"); !errors.Is(err, ErrUnknownMaster) {
+		t.Errorf("Load of an unknown master error = %v, want ErrUnknownMaster", err)
+	}
+
+	l, err = NewCsvLoader(strings.NewReader("code,name\n"), StructureConfig{Code: "code", Name: "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if models, err = l.Load(context.Background(), "gender"); err != nil || len(models) != 0 {
+		t.Errorf("Load of an empty csv without master = %v, %v, want no codes", models, err)
+	}
+}
@@ -0,0 +1,203 @@
+package code
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+type Dialect interface {
+	Name() string
+	Placeholder(i int) string
+	Limit(query string, limit int64, offset int64) string
+	Quote(identifier string) string
+	Fold(identifier string) string
+	Like(column string, param string, ignoreCase bool) string
+	Concat(values ...string) string
+}
+
+type SqlDialect struct {
+	DialectName string
+	Build       func(i int) string
+	Paging      func(query string, limit int64, offset int64) string
+	QuoteLeft   string
+	QuoteRight  string
+	Folding     func(identifier string) string
+	ILike       bool
+	Concatenate func(values ...string) string
+}
+
+func (d SqlDialect) Name() string {
+	return d.DialectName
+}
+func (d SqlDialect) Placeholder(i int) string {
+	if d.Build == nil {
+		return "?"
+	}
+	return d.Build(i)
+}
+func (d SqlDialect) Limit(query string, limit int64, offset int64) string {
+	if d.Paging == nil {
+		return buildLimitOffset(query, limit, offset)
+	}
+	return d.Paging(query, limit, offset)
+}
+func (d SqlDialect) Quote(identifier string) string {
+	if len(d.QuoteLeft) == 0 {
+		return identifier
+	}
+	right := d.QuoteRight
+	if len(right) == 0 {
+		right = d.QuoteLeft
+	}
+	return d.QuoteLeft + strings.Replace(identifier, right, right+right, -1) + right
+}
+func (d SqlDialect) Fold(identifier string) string {
+	if d.Folding == nil {
+		return identifier
+	}
+	return d.Folding(identifier)
+}
+
+// Like returns the like condition with the escape character of EscapeLike; the case insensitive condition uses ilike if the database supports it, else lower.
+func (d SqlDialect) Like(column string, param string, ignoreCase bool) string {
+	escape := fmt.Sprintf(" escape '%c'", likeEscape)
+	if !ignoreCase {
+		return column + " like " + param + escape
+	}
+	if d.ILike {
+		return column + " ilike " + param + escape
+	}
+	return fmt.Sprintf("lower(%s) like lower(%s)", column, param) + escape
+}
+func (d SqlDialect) Concat(values ...string) string {
+	if d.Concatenate == nil {
+		return "(" + strings.Join(values, " || ") + ")"
+	}
+	return d.Concatenate(values...)
+}
+
+var (
+	PostgresDialect Dialect = SqlDialect{DialectName: driverPostgres, Build: buildDollarParam, Paging: buildLimitOffset, QuoteLeft: `"`, Folding: strings.ToLower, ILike: true}
+	MySqlDialect    Dialect = SqlDialect{DialectName: driverMysql, Build: buildParam, Paging: buildLimitOffset, QuoteLeft: "`", Folding: strings.ToLower, Concatenate: buildConcat}
+	MsSqlDialect    Dialect = SqlDialect{DialectName: driverMssql, Build: buildMsSqlParam, Paging: buildMsSqlOffsetFetch, QuoteLeft: "[", QuoteRight: "]", Folding: strings.ToLower, Concatenate: buildConcat}
+	OracleDialect   Dialect = SqlDialect{DialectName: driverOracle, Build: buildOracleParam, Paging: buildOffsetFetch, QuoteLeft: `"`, Folding: strings.ToUpper}
+	// Oracle11Dialect pages with rownum, for Oracle before 12c, which has no offset fetch. It is not detected by the driver, so it must be passed to the constructors WithDialect, or registered with RegisterDialect.
+	Oracle11Dialect Dialect = SqlDialect{DialectName: driverOracle, Build: buildOracleParam, Paging: buildRownum, QuoteLeft: `"`, Folding: strings.ToUpper}
+	SqliteDialect   Dialect = SqlDialect{DialectName: driverSqlite3, Build: buildParam, Paging: buildLimitOffset, QuoteLeft: `"`, Folding: strings.ToLower}
+	DefaultDialect  Dialect = SqlDialect{DialectName: "default", Build: buildParam, Paging: buildLimitOffset, QuoteLeft: `"`, Folding: strings.ToLower}
+)
+
+var (
+	dialectMu sync.RWMutex
+	dialects  = map[string]Dialect{
+		driverPostgres:          PostgresDialect,
+		"pgx":                   PostgresDialect,
+		driverMysql:             MySqlDialect,
+		driverMssql:             MsSqlDialect,
+		"sqlserver":             MsSqlDialect,
+		driverOracle:            OracleDialect,
+		"godror":                OracleDialect,
+		driverSqlite3:           SqliteDialect,
+		"sqlite":                SqliteDialect,
+		"*pq.Driver":            PostgresDialect,
+		"*stdlib.Driver":        PostgresDialect,
+		"*mysql.MySQLDriver":    MySqlDialect,
+		"*mssql.Driver":         MsSqlDialect,
+		"*godror.drv":           OracleDialect,
+		"*go_ora.OracleDriver":  OracleDialect,
+		"*sqlite3.SQLiteDriver": SqliteDialect,
+		"*sqlite.Driver":        SqliteDialect,
+	}
+)
+
+// RegisterDialect registers the dialect by the driver name, such as "pgx", or by the type of the driver, such as "*stdlib.Driver".
+func RegisterDialect(name string, dialect Dialect) {
+	dialectMu.Lock()
+	defer dialectMu.Unlock()
+	dialects[name] = dialect
+}
+func RegisterDriverDialect(drv driver.Driver, dialect Dialect) {
+	RegisterDialect(reflect.TypeOf(drv).String(), dialect)
+}
+func GetDialect(name string) (Dialect, bool) {
+	dialectMu.RLock()
+	defer dialectMu.RUnlock()
+	dialect, ok := dialects[name]
+	return dialect, ok
+}
+
+// DetectDialect returns the dialect registered for the type of the driver of db, or DefaultDialect if there is none.
+func DetectDialect(db *sql.DB) Dialect {
+	if db == nil {
+		return DefaultDialect
+	}
+	if dialect, ok := GetDialect(reflect.TypeOf(db.Driver()).String()); ok {
+		return dialect
+	}
+	return DefaultDialect
+}
+func buildParam(i int) string {
+	return "?"
+}
+func buildOracleParam(i int) string {
+	return ":val" + strconv.Itoa(i)
+}
+func buildMsSqlParam(i int) string {
+	return "@p" + strconv.Itoa(i)
+}
+func buildDollarParam(i int) string {
+	return "$" + strconv.Itoa(i)
+}
+func buildLimitOffset(query string, limit int64, offset int64) string {
+	if offset > 0 {
+		return query + fmt.Sprintf(" limit %d offset %d", limit, offset)
+	}
+	return query + fmt.Sprintf(" limit %d", limit)
+}
+func buildOffsetFetch(query string, limit int64, offset int64) string {
+	if offset > 0 {
+		return query + fmt.Sprintf(" offset %d rows fetch next %d rows only", offset, limit)
+	}
+	return query + fmt.Sprintf(" fetch next %d rows only", limit)
+}
+
+// buildMsSqlOffsetFetch uses top if the query has no order by clause, which offset and fetch require.
+// A query with top is wrapped, as top cannot be used with offset and fetch.
+func buildMsSqlOffsetFetch(query string, limit int64, offset int64) string {
+	tokens := tokenize(query)
+	if hasKeywords(tokens, "select", "top") || hasKeywords(tokens, "select", "distinct", "top") || hasKeywords(tokens, "select", "all", "top") {
+		if offset <= 0 {
+			return fmt.Sprintf("select top %d * from (%s) q__", limit, query)
+		}
+		return fmt.Sprintf("select * from (%s) q__ order by (select null) offset %d rows fetch next %d rows only", query, offset, limit)
+	}
+	if !hasKeywords(tokens, "order", "by") {
+		if offset <= 0 {
+			trimmed := strings.TrimLeft(query, " \t\r\n")
+			lower := strings.ToLower(trimmed)
+			for _, prefix := range []string{"select distinct ", "select "} {
+				if strings.HasPrefix(lower, prefix) {
+					return trimmed[:len(prefix)] + fmt.Sprintf("top %d ", limit) + trimmed[len(prefix):]
+				}
+			}
+		}
+		query = query + " order by (select null)"
+	}
+	return query + fmt.Sprintf(" offset %d rows fetch next %d rows only", offset, limit)
+}
+
+// buildRownum wraps the query with rownum, for Oracle before 12c.
+func buildRownum(query string, limit int64, offset int64) string {
+	if offset <= 0 {
+		return fmt.Sprintf("select * from (%s) where rownum <= %d", query, limit)
+	}
+	return fmt.Sprintf("select * from (select q__.*, rownum rn__ from (%s) q__ where rownum <= %d) where rn__ > %d", query, offset+limit, offset)
+}
+func buildConcat(values ...string) string {
+	return "concat(" + strings.Join(values, ", ") + ")"
+}
@@ -0,0 +1,79 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		limit   int64
+		offset  int64
+		want    string
+	}{
+		{"postgres", PostgresDialect, "select code from t", 10, 0, "select code from t limit 10"},
+		{"postgres offset", PostgresDialect, "select code from t", 10, 20, "select code from t limit 10 offset 20"},
+		{"oracle", OracleDialect, "select code from t order by code", 10, 20, "select code from t order by code offset 20 rows fetch next 10 rows only"},
+		{"oracle 11", Oracle11Dialect, "select code from t", 10, 0, "select * from (select code from t) where rownum <= 10"},
+		{"oracle 11 offset", Oracle11Dialect, "select code from t", 10, 20, "select * from (select q__.*, rownum rn__ from (select code from t) q__ where rownum <= 30) where rn__ > 20"},
+		{"mssql top", MsSqlDialect, "select code from t", 10, 0, "select top 10 code from t"},
+		{"mssql distinct top", MsSqlDialect, "select distinct code from t", 10, 0, "select distinct top 10 code from t"},
+		{"mssql order by", MsSqlDialect, "select code from t order by code", 10, 0, "select code from t order by code offset 0 rows fetch next 10 rows only"},
+		{"mssql offset", MsSqlDialect, "select code from t", 10, 20, "select code from t order by (select null) offset 20 rows fetch next 10 rows only"},
+		{"mssql subquery order by", MsSqlDialect, "select code from t where code in (select top 5 code from u order by code)", 10, 20, "select code from t where code in (select top 5 code from u order by code) order by (select null) offset 20 rows fetch next 10 rows only"},
+		{"mssql query with top", MsSqlDialect, "select top 100 code from t", 10, 0, "select top 10 * from (select top 100 code from t) q__"},
+		{"mssql query with top offset", MsSqlDialect, "select top 100 code from t", 10, 20, "select * from (select top 100 code from t) q__ order by (select null) offset 20 rows fetch next 10 rows only"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Limit(tt.query, tt.limit, tt.offset); got != tt.want {
+				t.Errorf("Limit(%q, %d, %d) = %q, want %q", tt.query, tt.limit, tt.offset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasKeywords(t *testing.T) {
+	tests := []struct {
+		query string
+		want  bool
+	}{
+		{"select a from t order by a", true},
+		{"select a from t ORDER  BY a", true},
+		{"select a from t where b in (select b from u order by b)", false},
+		{"select a, 'order by' from t", false},
+		{"select a from t -- order by a", false},
+		{`select "order", by from t`, false},
+	}
+	for _, tt := range tests {
+		if got := hasKeywords(tokenize(tt.query), "order", "by"); got != tt.want {
+			t.Errorf("hasKeywords(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestPageQueryHandler(t *testing.T) {
+	var offsets []int64
+	getPage := func(ctx context.Context, key string, max int64, offset int64) ([]Model, error) {
+		offsets = append(offsets, offset)
+		return []Model{{Code: key}}, nil
+	}
+	h := NewPageQueryHandler(getPage, nil, nil)
+	for _, target := range []string{"/codes?q=a&max=10", "/codes?q=a&max=10&offset=30", "/codes?q=a&max=10&page=3"} {
+		w := httptest.NewRecorder()
+		h.Query(w, httptest.NewRequest(http.MethodGet, target, nil))
+		var models []Model
+		if err := json.NewDecoder(w.Body).Decode(&models); err != nil || len(models) != 1 {
+			t.Errorf("Query(%q) = %v, %v, want the page", target, models, err)
+		}
+	}
+	if len(offsets) != 3 || offsets[0] != 0 || offsets[1] != 30 || offsets[2] != 20 {
+		t.Errorf("offsets = %v, want 0, 30, 20", offsets)
+	}
+}
@@ -0,0 +1,300 @@
+package dynamodb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	co "github.com/core-go/code"
+)
+
+const (
+	batchSize  = 100
+	maxRetries = 8
+	retryDelay = 50 * time.Millisecond
+	maxDelay   = 5 * time.Second
+)
+
+type Loader struct {
+	Client *dynamodb.Client
+	Table  string
+	Index  string
+	Config co.StructureConfig
+	fields map[string]int
+}
+
+func NewCodeLoader(client *dynamodb.Client, table string, config co.StructureConfig, options ...string) *Loader {
+	var index string
+	if len(options) > 0 {
+		index = options[0]
+	}
+	return &Loader{Client: client, Table: table, Index: index, Config: config, fields: getAttributes(config)}
+}
+func (l Loader) Load(ctx context.Context, master string) ([]co.Model, error) {
+	names := make(map[string]string)
+	values := make(map[string]types.AttributeValue)
+	projection := buildProjection(l.fields, names)
+	filter := ""
+	if len(l.Config.Status) > 0 && l.Config.Active != nil {
+		names["#status"] = l.Config.Status
+		values[":active"] = toAttributeValue(l.Config.Active)
+		filter = "#status = :active"
+	}
+	items := make([]map[string]types.AttributeValue, 0)
+	var start map[string]types.AttributeValue
+	if len(l.Config.Master) > 0 {
+		names["#master"] = l.Config.Master
+		values[":master"] = &types.AttributeValueMemberS{Value: master}
+		for {
+			input := &dynamodb.QueryInput{
+				TableName:                 aws.String(l.Table),
+				KeyConditionExpression:    aws.String("#master = :master"),
+				ProjectionExpression:      aws.String(projection),
+				ExpressionAttributeNames:  names,
+				ExpressionAttributeValues: values,
+				ExclusiveStartKey:         start,
+			}
+			if len(l.Index) > 0 {
+				input.IndexName = aws.String(l.Index)
+			}
+			if len(filter) > 0 {
+				input.FilterExpression = aws.String(filter)
+			}
+			output, err := l.Client.Query(ctx, input)
+			if err != nil {
+				return nil, co.ClassifyError(err)
+			}
+			items = append(items, output.Items...)
+			if len(output.LastEvaluatedKey) == 0 {
+				break
+			}
+			start = output.LastEvaluatedKey
+		}
+	} else {
+		for {
+			input := &dynamodb.ScanInput{
+				TableName:                aws.String(l.Table),
+				ProjectionExpression:     aws.String(projection),
+				ExpressionAttributeNames: names,
+				ExclusiveStartKey:        start,
+			}
+			if len(l.Index) > 0 {
+				input.IndexName = aws.String(l.Index)
+			}
+			if len(filter) > 0 {
+				input.FilterExpression = aws.String(filter)
+				input.ExpressionAttributeValues = values
+			}
+			output, err := l.Client.Scan(ctx, input)
+			if err != nil {
+				return nil, co.ClassifyError(err)
+			}
+			items = append(items, output.Items...)
+			if len(output.LastEvaluatedKey) == 0 {
+				break
+			}
+			start = output.LastEvaluatedKey
+		}
+	}
+	models, err := toModels(items, l.fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(l.Config.Sequence) > 0 {
+		sort.SliceStable(models, func(i, j int) bool {
+			return models[i].Sequence < models[j].Sequence
+		})
+	}
+	return models, nil
+}
+
+type Query struct {
+	Client *dynamodb.Client
+	Table  string
+	Key    string
+	Config co.StructureConfig
+	fields map[string]int
+}
+
+func NewQuery(client *dynamodb.Client, table string, config co.StructureConfig, options ...string) *Query {
+	var key string
+	if len(options) > 0 && len(options[0]) > 0 {
+		key = options[0]
+	} else if len(config.Id) > 0 {
+		key = config.Id
+	} else {
+		key = "id"
+	}
+	return &Query{Client: client, Table: table, Key: key, Config: config, fields: getAttributes(config)}
+}
+func (q Query) Load(ctx context.Context, keys []string) ([]co.Model, error) {
+	items := make([]map[string]types.AttributeValue, 0)
+	names := make(map[string]string)
+	var projection string
+	if len(q.Config.Status) > 0 && q.Config.Active != nil {
+		projection = buildProjection(q.fields, names, q.Config.Status)
+	} else {
+		projection = buildProjection(q.fields, names)
+	}
+	requests := make([]map[string]types.AttributeValue, 0)
+	unique := make(map[string]bool)
+	for _, key := range keys {
+		if !unique[key] {
+			unique[key] = true
+			requests = append(requests, map[string]types.AttributeValue{q.Key: &types.AttributeValueMemberS{Value: key}})
+		}
+	}
+	for i := 0; i < len(requests); i += batchSize {
+		end := i + batchSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		input := map[string]types.KeysAndAttributes{
+			q.Table: {Keys: requests[i:end], ProjectionExpression: aws.String(projection), ExpressionAttributeNames: names},
+		}
+		for retry := 0; len(input) > 0; retry++ {
+			if retry > 0 {
+				if retry > maxRetries {
+					return nil, &co.KindError{Kind: co.ErrUnavailable, Err: fmt.Errorf("unprocessed keys of table '%s' after %d retries", q.Table, maxRetries)}
+				}
+				if err := wait(ctx, retry); err != nil {
+					return nil, co.ClassifyError(err)
+				}
+			}
+			output, err := q.Client.BatchGetItem(ctx, &dynamodb.BatchGetItemInput{RequestItems: input})
+			if err != nil {
+				return nil, co.ClassifyError(err)
+			}
+			items = append(items, output.Responses[q.Table]...)
+			input = output.UnprocessedKeys
+		}
+	}
+	models, err := toModels(items, q.fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Config.Status) > 0 && q.Config.Active != nil {
+		active := fmt.Sprint(q.Config.Active)
+		status := make([]co.Model, 0)
+		for i, item := range items {
+			if v, ok := item[q.Config.Status]; ok && toString(v) == active {
+				status = append(status, models[i])
+			}
+		}
+		models = status
+	}
+	return models, nil
+}
+
+// wait backs off exponentially before retrying the unprocessed keys, as the keys are unprocessed when the table is throttled.
+func wait(ctx context.Context, retry int) error {
+	delay := retryDelay << uint(retry-1)
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// getAttributes maps the attribute names of the table to the fields of co.Model. When a field is not configured, the dynamodbav tag is used.
+func getAttributes(c co.StructureConfig) map[string]int {
+	configured := map[string]string{"id": c.Id, "code": c.Code, "value": c.Value, "name": c.Name, "text": c.Text, "parent": c.Parent, "sequence": c.Sequence}
+	modelType := reflect.TypeOf(co.Model{})
+	fields := make(map[string]int)
+	for i := 0; i < modelType.NumField(); i++ {
+		tag := modelType.Field(i).Tag.Get("dynamodbav")
+		name := strings.Split(tag, ",")[0]
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		if attr, ok := configured[name]; ok && len(attr) > 0 {
+			fields[attr] = i
+		} else {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+func buildProjection(fields map[string]int, names map[string]string, extra ...string) string {
+	s := make([]string, 0)
+	for attr, i := range fields {
+		k := "#f" + strconv.Itoa(i)
+		names[k] = attr
+		s = append(s, k)
+	}
+	sort.Strings(s)
+	for i, attr := range extra {
+		if _, ok := fields[attr]; ok {
+			// a path cannot be projected twice
+			continue
+		}
+		k := "#x" + strconv.Itoa(i)
+		names[k] = attr
+		s = append(s, k)
+	}
+	return strings.Join(s, ",")
+}
+func toModels(items []map[string]types.AttributeValue, fields map[string]int) ([]co.Model, error) {
+	models := make([]co.Model, 0)
+	for _, item := range items {
+		var model co.Model
+		v := reflect.ValueOf(&model).Elem()
+		for attr, i := range fields {
+			av, ok := item[attr]
+			if !ok {
+				continue
+			}
+			s := toString(av)
+			f := v.Field(i)
+			switch f.Kind() {
+			case reflect.String:
+				f.SetString(s)
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				n, err := strconv.ParseInt(s, 10, 64)
+				if err != nil {
+					return nil, &co.ScanError{Row: len(models) + 1, Err: fmt.Errorf("cannot convert attribute %s with value '%s' to %s", attr, s, f.Kind())}
+				}
+				f.SetInt(n)
+			}
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
+func toString(av types.AttributeValue) string {
+	switch v := av.(type) {
+	case *types.AttributeValueMemberS:
+		return v.Value
+	case *types.AttributeValueMemberN:
+		return v.Value
+	case *types.AttributeValueMemberBOOL:
+		return strconv.FormatBool(v.Value)
+	default:
+		return ""
+	}
+}
+func toAttributeValue(v interface{}) types.AttributeValue {
+	switch x := v.(type) {
+	case string:
+		return &types.AttributeValueMemberS{Value: x}
+	case bool:
+		return &types.AttributeValueMemberBOOL{Value: x}
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return &types.AttributeValueMemberN{Value: fmt.Sprint(x)}
+	default:
+		return &types.AttributeValueMemberS{Value: fmt.Sprint(x)}
+	}
+}
@@ -11,10 +11,9 @@ import (
 	"strings"
 )
 
-const internalServerError = "Internal Server Error"
-
-type Handler struct {
-	Codes          func(ctx context.Context, master string) ([]co.Model, error)
+type Handler = GenericHandler[co.Model]
+type GenericHandler[T any] struct {
+	Codes          func(ctx context.Context, master string) ([]T, error)
 	RequiredMaster bool
 	Error          func(context.Context, string, ...map[string]interface{})
 	Log            func(ctx context.Context, resource string, action string, success bool, desc string) error
@@ -22,16 +21,30 @@ type Handler struct {
 	Action         string
 	Id             string
 	Name           string
+	Locales        []string
+	DefaultLocale  string
+	LocaleParam    string
+	Tree           string
+	TreeParam      string
+	AsOfParam      string
+	Many           func(ctx context.Context, masters []string) (map[string][]T, error)
+	MastersParam   string
 }
 
 func NewDefaultCodeHandler(load func(ctx context.Context, master string) ([]co.Model, error), logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *Handler {
+	return NewDefaultGenericCodeHandler[co.Model](load, logError, options...)
+}
+func NewDefaultGenericCodeHandler[T any](load func(ctx context.Context, master string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *GenericHandler[T] {
 	var writeLog func(context.Context, string, string, bool, string) error
 	if len(options) >= 1 {
 		writeLog = options[0]
 	}
-	return NewCodeHandlerWithLog(load, logError, true, writeLog, "", "")
+	return NewGenericCodeHandlerWithLog[T](load, logError, true, writeLog, "", "")
 }
 func NewCodeHandlerByConfig(load func(ctx context.Context, master string) ([]co.Model, error), c co.HandlerConfig, logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *Handler {
+	return NewGenericCodeHandlerByConfig[co.Model](load, c, logError, options...)
+}
+func NewGenericCodeHandlerByConfig[T any](load func(ctx context.Context, master string) ([]T, error), c co.HandlerConfig, logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *GenericHandler[T] {
 	var requireMaster bool
 	if c.Master != nil {
 		requireMaster = *c.Master
@@ -42,19 +55,43 @@ func NewCodeHandlerByConfig(load func(ctx context.Context, master string) ([]co.
 	if len(options) >= 1 {
 		writeLog = options[0]
 	}
-	h := NewCodeHandlerWithLog(load, logError, requireMaster, writeLog, c.Resource, c.Action)
+	h := NewGenericCodeHandlerWithLog[T](load, logError, requireMaster, writeLog, c.Resource, c.Action)
 	h.Id = c.Id
 	h.Name = c.Name
+	h.Locales = c.Locales
+	h.DefaultLocale = c.DefaultLocale
+	if len(c.LocaleParam) > 0 {
+		h.LocaleParam = c.LocaleParam
+	} else {
+		h.LocaleParam = "locale"
+	}
+	h.Tree = c.Tree
+	if len(c.TreeParam) > 0 {
+		h.TreeParam = c.TreeParam
+	} else {
+		h.TreeParam = "tree"
+	}
+	if len(c.AsOfParam) > 0 {
+		h.AsOfParam = c.AsOfParam
+	} else {
+		h.AsOfParam = "asOf"
+	}
 	return h
 }
 func NewCodeHandler(load func(ctx context.Context, master string) ([]co.Model, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, options ...func(context.Context, string, string, bool, string) error) *Handler {
+	return NewGenericCodeHandler[co.Model](load, logError, requiredMaster, options...)
+}
+func NewGenericCodeHandler[T any](load func(ctx context.Context, master string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, options ...func(context.Context, string, string, bool, string) error) *GenericHandler[T] {
 	var writeLog func(context.Context, string, string, bool, string) error
 	if len(options) >= 1 {
 		writeLog = options[0]
 	}
-	return NewCodeHandlerWithLog(load, logError, requiredMaster, writeLog, "", "")
+	return NewGenericCodeHandlerWithLog[T](load, logError, requiredMaster, writeLog, "", "")
 }
 func NewCodeHandlerWithLog(load func(ctx context.Context, master string) ([]co.Model, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, writeLog func(context.Context, string, string, bool, string) error, options ...string) *Handler {
+	return NewGenericCodeHandlerWithLog[co.Model](load, logError, requiredMaster, writeLog, options...)
+}
+func NewGenericCodeHandlerWithLog[T any](load func(ctx context.Context, master string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, writeLog func(context.Context, string, string, bool, string) error, options ...string) *GenericHandler[T] {
 	var resource, action string
 	if len(options) >= 1 && len(options[0]) > 0 {
 		resource = options[0]
@@ -66,17 +103,17 @@ func NewCodeHandlerWithLog(load func(ctx context.Context, master string) ([]co.M
 	} else {
 		action = "load"
 	}
-	h := Handler{Codes: load, Resource: resource, Action: action, RequiredMaster: requiredMaster, Log: writeLog, Error: logError}
+	h := GenericHandler[T]{Codes: load, Resource: resource, Action: action, RequiredMaster: requiredMaster, Log: writeLog, Error: logError, MastersParam: "masters"}
 	return &h
 }
-func (h *Handler) Load(ctx echo.Context) error {
+func (h *GenericHandler[T]) Load(ctx echo.Context) error {
 	r := ctx.Request()
 	code := ""
 	if h.RequiredMaster {
 		if r.Method == "GET" {
-			i := strings.LastIndex(r.RequestURI, "/")
+			i := strings.LastIndex(r.URL.Path, "/")
 			if i >= 0 {
-				code = r.RequestURI[i+1:]
+				code = r.URL.Path[i+1:]
 			}
 		} else {
 			b, er1 := ioutil.ReadAll(r.Body)
@@ -87,35 +124,106 @@ func (h *Handler) Load(ctx echo.Context) error {
 			code = strings.Trim(string(b), " ")
 		}
 	}
-	result, er4 := h.Codes(r.Context(), code)
+	mode, er2 := co.GetTreeMode(r, h.TreeParam, h.Tree)
+	if er2 != nil {
+		ctx.String(http.StatusBadRequest, er2.Error())
+		return er2
+	}
+	asOf, er3 := co.GetRequestAsOf(r, h.AsOfParam)
+	if er3 != nil {
+		ctx.String(http.StatusBadRequest, er3.Error())
+		return er3
+	}
+	lc := setLocale(ctx, h.LocaleParam, h.Locales, h.DefaultLocale)
+	if asOf != nil {
+		lc = co.WithAsOf(lc, *asOf)
+	}
+	c, stale := co.WithStaleFlag(lc)
+	result, er4 := h.Codes(c, code)
 	if er4 != nil {
-		return respondError(ctx, http.StatusInternalServerError, internalServerError, h.Error, h.Resource, h.Action, er4, h.Log)
+		return respondError(ctx, h.Error, h.Resource, h.Action, er4, h.Log)
 	} else {
-		if len(h.Id) == 0 && len(h.Name) == 0 {
+		if *stale {
+			ctx.Response().Header().Set(co.StaleHeader, "true")
+		}
+		if len(mode) > 0 {
+			tree, er5 := co.ToTree(result, mode)
+			if er5 != nil {
+				return respondError(ctx, h.Error, h.Resource, h.Action, er5, h.Log)
+			}
+			return succeed(ctx, http.StatusOK, tree, h.Log, h.Resource, h.Action)
+		} else if len(h.Id) == 0 && len(h.Name) == 0 {
 			return succeed(ctx, http.StatusOK, result, h.Log, h.Resource, h.Action)
 		} else {
-			rs := make([]map[string]string, 0)
-			for _, r := range result {
-				m := make(map[string]string)
-				m[h.Id] = r.Id
-				m[h.Name] = r.Name
-				rs = append(rs, m)
-			}
+			rs := co.MapIdName(result, h.Id, h.Name)
 			return succeed(ctx, http.StatusOK, rs, h.Log, h.Resource, h.Action)
 		}
 	}
 }
+func (h *GenericHandler[T]) LoadMany(ctx echo.Context) error {
+	r := ctx.Request()
+	masters := make([]string, 0)
+	if r.Method == http.MethodGet {
+		if q := r.URL.Query().Get(h.MastersParam); len(q) > 0 {
+			masters = strings.Split(q, ",")
+		}
+	} else {
+		er1 := json.NewDecoder(r.Body).Decode(&masters)
+		if er1 != nil {
+			ctx.String(http.StatusBadRequest, er1.Error())
+			return er1
+		}
+	}
+	mode, er2 := co.GetTreeMode(r, h.TreeParam, h.Tree)
+	if er2 != nil {
+		ctx.String(http.StatusBadRequest, er2.Error())
+		return er2
+	}
+	asOf, er3 := co.GetRequestAsOf(r, h.AsOfParam)
+	if er3 != nil {
+		ctx.String(http.StatusBadRequest, er3.Error())
+		return er3
+	}
+	lc := setLocale(ctx, h.LocaleParam, h.Locales, h.DefaultLocale)
+	if asOf != nil {
+		lc = co.WithAsOf(lc, *asOf)
+	}
+	c, stale := co.WithStaleFlag(lc)
+	result, er4 := co.LoadMasters(c, masters, h.Many, h.Codes)
+	if er4 != nil {
+		return respondError(ctx, h.Error, h.Resource, h.Action, er4, h.Log)
+	}
+	if *stale {
+		ctx.Response().Header().Set(co.StaleHeader, "true")
+	}
+	rs, er5 := co.FormatCodes(result, mode, h.Id, h.Name)
+	if er5 != nil {
+		return respondError(ctx, h.Error, h.Resource, h.Action, er5, h.Log)
+	}
+	return succeed(ctx, http.StatusOK, rs, h.Log, h.Resource, h.Action)
+}
 
-type QueryHandler struct {
-	Get      func(ctx context.Context, key string, max int64) ([]co.Model, error)
-	Select   func(ctx context.Context, key []string) ([]co.Model, error)
-	LogError func(context.Context, string, ...map[string]interface{})
-	Keyword  string
-	Max      string
-	Q        string
+type QueryHandler = GenericQueryHandler[co.Model]
+type GenericQueryHandler[T any] struct {
+	Get           func(ctx context.Context, key string, max int64) ([]T, error)
+	Select        func(ctx context.Context, key []string) ([]T, error)
+	LogError      func(context.Context, string, ...map[string]interface{})
+	Keyword       string
+	Max           string
+	Q             string
+	Offset        string
+	Page          string
+	Match         string
+	GetPage       func(ctx context.Context, key string, max int64, offset int64) ([]T, error)
+	Locales       []string
+	DefaultLocale string
+	LocaleParam   string
 }
 
 func NewQueryHandler(load func(ctx context.Context, key string, max int64) ([]co.Model, error), getData func(ctx context.Context, key []string) ([]co.Model, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *QueryHandler {
+	return NewGenericQueryHandler[co.Model](load, getData, logError, opts...)
+}
+func NewGenericQueryHandler[T any](load func(ctx context.Context, key string, max int64) ([]T, error), getData func(ctx context.Context, key []string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *GenericQueryHandler[T] {
 	q := "q"
 	if len(opts) > 0 && len(opts[0]) > 0 {
 		q = opts[0]
@@ -128,10 +236,34 @@ func NewQueryHandler(load func(ctx context.Context, key string, max int64) ([]co
 	if len(opts) > 2 && len(opts[2]) > 0 {
 		max = opts[2]
 	}
-	return &QueryHandler{load, getData, logError, keyword, max, q}
+	offset := "offset"
+	if len(opts) > 3 && len(opts[3]) > 0 {
+		offset = opts[3]
+	}
+	page := "page"
+	if len(opts) > 4 && len(opts[4]) > 0 {
+		page = opts[4]
+	}
+	match := "match"
+	if len(opts) > 5 && len(opts[5]) > 0 {
+		match = opts[5]
+	}
+	return &GenericQueryHandler[T]{Get: load, Select: getData, LogError: logError, Keyword: keyword, Max: max, Q: q, Offset: offset, Page: page, Match: match}
 }
 
-func (h *QueryHandler) Query(ctx echo.Context) error {
+// NewPageQueryHandler queries the pages with getPage, such as Query.QueryPage, so that the next pages are loaded by offset.
+func NewPageQueryHandler(getPage func(ctx context.Context, key string, max int64, offset int64) ([]co.Model, error), getData func(ctx context.Context, key []string) ([]co.Model, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *QueryHandler {
+	return NewGenericPageQueryHandler[co.Model](getPage, getData, logError, opts...)
+}
+func NewGenericPageQueryHandler[T any](getPage func(ctx context.Context, key string, max int64, offset int64) ([]T, error), getData func(ctx context.Context, key []string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *GenericQueryHandler[T] {
+	load := func(ctx context.Context, key string, max int64) ([]T, error) {
+		return getPage(ctx, key, max, 0)
+	}
+	h := NewGenericQueryHandler[T](load, getData, logError, opts...)
+	h.GetPage = getPage
+	return h
+}
+func (h *GenericQueryHandler[T]) Query(ctx echo.Context) error {
 	ps := ctx.Request().URL.Query()
 	keyword := ps.Get(h.Keyword)
 	if len(keyword) == 0 {
@@ -146,16 +278,29 @@ func (h *QueryHandler) Query(ctx echo.Context) error {
 		if i < 0 {
 			i = 20
 		}
-		vs, err := h.Get(ctx.Request().Context(), keyword, i)
+		offset := co.GetOffset(ctx.Request(), h.Offset, h.Page, i)
+		var vs []T
+		// the locale is passed to the query functions, without Content-Language, as not all the queries translate the names
+		lc, _ := getLocale(ctx, h.LocaleParam, h.Locales, h.DefaultLocale)
+		match, err := co.GetRequestMatch(ctx.Request(), h.Match)
+		if err == nil {
+			if len(match) > 0 {
+				lc = co.WithMatch(lc, match)
+			}
+			vs, err = co.QueryPage(lc, keyword, i, offset, h.GetPage, h.Get)
+		}
 		if err != nil {
-			h.LogError(ctx.Request().Context(), err.Error())
-			return ctx.String(http.StatusInternalServerError, internalServerError)
+			status, message := co.GetErrorStatus(err)
+			if status >= http.StatusInternalServerError || message != err.Error() {
+				h.LogError(ctx.Request().Context(), err.Error())
+			}
+			return ctx.String(status, message)
 		} else {
 			return ctx.JSON(http.StatusOK, vs)
 		}
 	}
 }
-func (h *QueryHandler) Load(ctx echo.Context) error {
+func (h *GenericQueryHandler[T]) Load(ctx echo.Context) error {
 	r := ctx.Request()
 	var req = make([]string, 0)
 	method := r.Method
@@ -173,10 +318,14 @@ func (h *QueryHandler) Load(ctx echo.Context) error {
 	if len(req) == 0 {
 		return ctx.JSON(http.StatusOK, req)
 	}
-	models, err := h.Select(r.Context(), req)
+	lc, _ := getLocale(ctx, h.LocaleParam, h.Locales, h.DefaultLocale)
+	models, err := h.Select(lc, req)
 	if err != nil {
-		h.LogError(r.Context(), err.Error())
-		return ctx.String(http.StatusInternalServerError, internalServerError)
+		status, message := co.GetErrorStatus(err)
+		if status >= http.StatusInternalServerError || message != err.Error() {
+			h.LogError(r.Context(), err.Error())
+		}
+		return ctx.String(status, message)
 	} else {
 		return ctx.JSON(http.StatusOK, models)
 	}
@@ -188,13 +337,29 @@ func respond(ctx echo.Context, code int, result interface{}, writeLog func(conte
 	}
 	return err
 }
-func respondError(ctx echo.Context, code int, result interface{}, logError func(context.Context, string, ...map[string]interface{}), resource string, action string, err error, writeLog func(context.Context, string, string, bool, string) error) error {
-	if logError != nil {
+func respondError(ctx echo.Context, logError func(context.Context, string, ...map[string]interface{}), resource string, action string, err error, writeLog func(context.Context, string, string, bool, string) error) error {
+	status, message := co.GetErrorStatus(err)
+	if logError != nil && (status >= http.StatusInternalServerError || message != err.Error()) {
 		logError(ctx.Request().Context(), err.Error())
 	}
-	respond(ctx, code, result, writeLog, resource, action, false, err.Error())
+	respond(ctx, status, message, writeLog, resource, action, false, err.Error())
 	return err
 }
 func succeed(ctx echo.Context, code int, result interface{}, writeLog func(context.Context, string, string, bool, string) error, resource string, action string) error {
 	return respond(ctx, code, result, writeLog, resource, action, true, "")
 }
+func setLocale(ctx echo.Context, param string, locales []string, defaultLocale string) context.Context {
+	lc, locale := getLocale(ctx, param, locales, defaultLocale)
+	if len(locale) > 0 {
+		ctx.Response().Header().Set(co.LocaleHeader, locale)
+	}
+	return lc
+}
+
+// getLocale returns the context with the locale of the request. The response varies with Accept-Language if the locales are configured.
+func getLocale(ctx echo.Context, param string, locales []string, defaultLocale string) (context.Context, string) {
+	if len(locales) > 0 {
+		ctx.Response().Header().Add("Vary", "Accept-Language")
+	}
+	return co.WithRequestLocale(ctx.Request(), param, locales, defaultLocale)
+}
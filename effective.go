@@ -0,0 +1,88 @@
+package code
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+type asOfKey struct{}
+type boundaryKey struct{}
+
+func WithAsOf(ctx context.Context, asOf time.Time) context.Context {
+	return context.WithValue(ctx, asOfKey{}, asOf)
+}
+
+// GetAsOf returns the reference time of the effective codes, which is now if it is not set in the context.
+func GetAsOf(ctx context.Context) (time.Time, bool) {
+	if asOf, ok := ctx.Value(asOfKey{}).(time.Time); ok {
+		return asOf, true
+	}
+	return time.Now(), false
+}
+
+// WithBoundary asks the loader to record the next time at which the effective codes change.
+func WithBoundary(ctx context.Context) (context.Context, *time.Time) {
+	var boundary time.Time
+	return context.WithValue(ctx, boundaryKey{}, &boundary), &boundary
+}
+func hasBoundary(ctx context.Context) bool {
+	boundary, ok := ctx.Value(boundaryKey{}).(*time.Time)
+	return ok && boundary != nil
+}
+func setBoundary(ctx context.Context, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	if boundary, ok := ctx.Value(boundaryKey{}).(*time.Time); ok && boundary != nil {
+		if boundary.IsZero() || t.Before(*boundary) {
+			*boundary = t
+		}
+	}
+}
+func IsEffective(m Model, asOf time.Time) bool {
+	if m.ValidFrom != nil && m.ValidFrom.After(asOf) {
+		return false
+	}
+	if m.ValidTo != nil && !m.ValidTo.After(asOf) {
+		return false
+	}
+	return true
+}
+func FilterEffective(models []Model, asOf time.Time) []Model {
+	result := make([]Model, 0)
+	for _, m := range models {
+		if IsEffective(m, asOf) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// NextBoundary returns the earliest valid from or valid to after the reference time, or zero time if there is none.
+func NextBoundary(models []Model, asOf time.Time) time.Time {
+	var next time.Time
+	for _, m := range models {
+		for _, t := range []*time.Time{m.ValidFrom, m.ValidTo} {
+			if t != nil && t.After(asOf) && (next.IsZero() || t.Before(next)) {
+				next = *t
+			}
+		}
+	}
+	return next
+}
+func GetRequestAsOf(r *http.Request, param string) (*time.Time, error) {
+	if len(param) == 0 {
+		return nil, nil
+	}
+	s := r.URL.Query().Get(param)
+	if len(s) == 0 {
+		return nil, nil
+	}
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, invalidInput("'%s' of parameter '%s' is not a valid date", s, param)
+}
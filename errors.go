@@ -0,0 +1,111 @@
+package code
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+var (
+	ErrUnknownMaster = errors.New("unknown master")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrScan          = errors.New("cannot scan code")
+	ErrTimeout       = errors.New("code source timed out")
+	ErrUnavailable   = errors.New("code source is unavailable")
+)
+
+// KindError is a failure of a kind, one of the sentinel errors, which the handlers map to a HTTP status.
+// Message is the message for the clients, if the error has details to be logged only, such as the URL of a remote code source.
+type KindError struct {
+	Kind    error
+	Err     error
+	Message string
+}
+
+func (e *KindError) Error() string {
+	return e.Err.Error()
+}
+func (e *KindError) Unwrap() error {
+	return e.Err
+}
+func (e *KindError) Is(target error) bool {
+	return target == e.Kind
+}
+
+type MasterError struct {
+	Master string
+}
+
+func (e *MasterError) Error() string {
+	return fmt.Sprintf("%s '%s'", ErrUnknownMaster.Error(), e.Master)
+}
+func (e *MasterError) Is(target error) bool {
+	return target == ErrUnknownMaster
+}
+
+type ScanError struct {
+	Row int
+	Err error
+}
+
+func (e *ScanError) Error() string {
+	return fmt.Sprintf("%s at row %d: %s", ErrScan.Error(), e.Row, e.Err.Error())
+}
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+func (e *ScanError) Is(target error) bool {
+	return target == ErrScan
+}
+func invalidInput(format string, args ...interface{}) error {
+	return &KindError{Kind: ErrInvalidInput, Err: fmt.Errorf(format, args...)}
+}
+
+// ClassifyError marks the timeouts and the connection failures with ErrTimeout and ErrUnavailable.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, kind := range []error{ErrUnknownMaster, ErrInvalidInput, ErrScan, ErrTimeout, ErrUnavailable} {
+		if errors.Is(err, kind) {
+			return err
+		}
+	}
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return &KindError{Kind: ErrTimeout, Err: err}
+	}
+	var opErr *net.OpError
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || errors.Is(err, ErrClosed) || errors.As(err, &opErr) {
+		return &KindError{Kind: ErrUnavailable, Err: err}
+	}
+	return err
+}
+
+// GetErrorStatus returns the HTTP status of the error, and the message to respond: the error or its client message for the client errors, else the status text.
+// The errors are logged if the message is not the error.
+func GetErrorStatus(err error) (int, string) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, ErrInvalidInput):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrUnknownMaster):
+		status = http.StatusNotFound
+	case errors.Is(err, ErrTimeout), errors.Is(err, context.DeadlineExceeded):
+		status = http.StatusGatewayTimeout
+	case errors.Is(err, ErrUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	if status < http.StatusInternalServerError {
+		var kindErr *KindError
+		if errors.As(err, &kindErr) && len(kindErr.Message) > 0 {
+			return status, kindErr.Message
+		}
+		return status, err.Error()
+	}
+	return status, http.StatusText(status)
+}
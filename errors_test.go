@@ -0,0 +1,80 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestGetErrorStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		status  int
+		message string
+	}{
+		{"unknown master", &MasterError{Master: "x"}, http.StatusNotFound, "unknown master 'x'"},
+		{"invalid input", invalidInput("tree mode '%s' is not supported", "deep"), http.StatusBadRequest, "tree mode 'deep' is not supported"},
+		{"message", &KindError{Kind: ErrInvalidInput, Err: errors.New("GET http://internal/codes returned status 400"), Message: "invalid input"}, http.StatusBadRequest, "invalid input"},
+		{"timeout", ClassifyError(context.DeadlineExceeded), http.StatusGatewayTimeout, http.StatusText(http.StatusGatewayTimeout)},
+		{"unavailable", &KindError{Kind: ErrUnavailable, Err: errors.New("dial tcp 10.0.0.1:5432: connection refused")}, http.StatusServiceUnavailable, http.StatusText(http.StatusServiceUnavailable)},
+		{"scan", &ScanError{Row: 2, Err: errors.New("converting NULL to string")}, http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError)},
+		{"wrapped", fmt.Errorf("load: %w", &MasterError{Master: "x"}), http.StatusNotFound, "load: unknown master 'x'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, message := GetErrorStatus(tt.err)
+			if status != tt.status || message != tt.message {
+				t.Errorf("GetErrorStatus = %d, %q, want %d, %q", status, message, tt.status, tt.message)
+			}
+		})
+	}
+}
+
+func TestHttpLoaderStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/400":
+			http.Error(w, "bad master", http.StatusBadRequest)
+		case "/404":
+			http.Error(w, "no such master", http.StatusNotFound)
+		case "/503":
+			http.Error(w, "down", http.StatusServiceUnavailable)
+		case "/504":
+			http.Error(w, "slow", http.StatusGatewayTimeout)
+		default:
+			http.Error(w, "failed", http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		master string
+		kind   error
+		status int
+	}{
+		{"400", ErrInvalidInput, http.StatusBadRequest},
+		{"404", ErrUnknownMaster, http.StatusNotFound},
+		{"503", ErrUnavailable, http.StatusServiceUnavailable},
+		{"504", ErrTimeout, http.StatusGatewayTimeout},
+		{"500", nil, http.StatusInternalServerError},
+	}
+	l := NewHttpLoader(nil, server.URL+"/")
+	for _, tt := range tests {
+		_, err := l.Load(context.Background(), tt.master)
+		if tt.kind != nil && !errors.Is(err, tt.kind) {
+			t.Errorf("Load(%q) error = %v, want %v", tt.master, err, tt.kind)
+		}
+		status, message := GetErrorStatus(err)
+		if status != tt.status {
+			t.Errorf("Load(%q) status = %d, want %d", tt.master, status, tt.status)
+		}
+		if strings.Contains(message, server.URL) {
+			t.Errorf("Load(%q) message = %q, want the URL of the code source not exposed", tt.master, message)
+		}
+	}
+}
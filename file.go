@@ -0,0 +1,175 @@
+package code
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileLoader loads the codes of a JSON or YAML file, by the extension, or of the Unmarshal function if it is set.
+type FileLoader struct {
+	FS        fs.FS
+	Path      string
+	Unmarshal func(data []byte, v interface{}) error
+	codes     map[string][]Model
+}
+
+func NewFileLoader(fsys fs.FS, path string, options ...func(data []byte, v interface{}) error) (*FileLoader, error) {
+	var unmarshal func(data []byte, v interface{}) error
+	if len(options) > 0 && options[0] != nil {
+		unmarshal = options[0]
+	}
+	codes, err := readCatalog(fsys, path, unmarshal)
+	if err != nil {
+		return nil, err
+	}
+	return &FileLoader{FS: fsys, Path: path, Unmarshal: unmarshal, codes: codes}, nil
+}
+func (l FileLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	codes, ok := l.codes[master]
+	if !ok && len(master) > 0 {
+		// a file of codes without masters serves all masters, as CsvLoader without the master column
+		codes, ok = l.codes[""]
+	}
+	if !ok {
+		return nil, &MasterError{Master: master}
+	}
+	return copyModels(codes), nil
+}
+
+type FileQuery struct {
+	Models     []Model
+	Match      string
+	IgnoreCase bool
+	Rank       bool
+	Score      bool
+}
+
+func NewFileQuery(fsys fs.FS, path string, options ...func(data []byte, v interface{}) error) (*FileQuery, error) {
+	loader, err := NewFileLoader(fsys, path, options...)
+	if err != nil {
+		return nil, err
+	}
+	masters := make([]string, 0, len(loader.codes))
+	for master := range loader.codes {
+		masters = append(masters, master)
+	}
+	sort.Strings(masters)
+	models := make([]Model, 0)
+	for _, master := range masters {
+		models = append(models, loader.codes[master]...)
+	}
+	return &FileQuery{Models: models, Match: MatchPrefix}, nil
+}
+func (q FileQuery) Query(ctx context.Context, key string, max int64) ([]Model, error) {
+	match, err := ResolveMatch(ctx, q.Match)
+	if err != nil {
+		return nil, err
+	}
+	models := queryModels(q.Models, key, max, match, q.IgnoreCase, q.Rank)
+	if q.Score {
+		ScoreModels(models, key, q.IgnoreCase)
+	}
+	return models, nil
+}
+func (q FileQuery) Load(ctx context.Context, keys []string) ([]Model, error) {
+	return loadModels(q.Models, keys), nil
+}
+
+// readCatalog reads one file of masters, or a directory with one file per master.
+func readCatalog(fsys fs.FS, name string, unmarshal func(data []byte, v interface{}) error) (map[string][]Model, error) {
+	info, err := fs.Stat(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	codes := make(map[string][]Model)
+	if !info.IsDir() {
+		unmarshal := getUnmarshal(name, unmarshal)
+		data, er1 := fs.ReadFile(fsys, name)
+		if er1 != nil {
+			return nil, er1
+		}
+		if er2 := unmarshal(data, &codes); er2 != nil {
+			models := make([]Model, 0)
+			if er3 := unmarshal(data, &models); er3 != nil {
+				return nil, er2
+			}
+			codes = map[string][]Model{"": models}
+		}
+		return codes, nil
+	}
+	entries, err := fs.ReadDir(fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		data, er1 := fs.ReadFile(fsys, path.Join(name, entry.Name()))
+		if er1 != nil {
+			return nil, er1
+		}
+		models := make([]Model, 0)
+		if er2 := getUnmarshal(entry.Name(), unmarshal)(data, &models); er2 != nil {
+			return nil, er2
+		}
+		master := strings.TrimSuffix(entry.Name(), path.Ext(entry.Name()))
+		codes[master] = models
+	}
+	return codes, nil
+}
+func getUnmarshal(name string, unmarshal func(data []byte, v interface{}) error) func(data []byte, v interface{}) error {
+	if unmarshal != nil {
+		return unmarshal
+	}
+	switch strings.ToLower(path.Ext(name)) {
+	case ".yaml", ".yml":
+		return yaml.Unmarshal
+	default:
+		return json.Unmarshal
+	}
+}
+func queryModels(models []Model, key string, max int64, match string, ignoreCase bool, rank bool) []Model {
+	if max <= 0 {
+		max = 20
+	}
+	result := make([]Model, 0)
+	for _, m := range models {
+		if !rank && int64(len(result)) >= max {
+			break
+		}
+		if Match(m.Name, key, match, ignoreCase) || Match(m.Code, key, match, ignoreCase) {
+			result = append(result, m)
+		}
+	}
+	if rank {
+		// all the matched codes are ranked, before the max codes are taken
+		RankModels(result, key, ignoreCase)
+		if int64(len(result)) > max {
+			result = result[:max]
+		}
+	}
+	return result
+}
+func loadModels(models []Model, keys []string) []Model {
+	result := make([]Model, 0)
+	if len(keys) == 0 {
+		return result
+	}
+	ks := make(map[string]bool)
+	for _, key := range keys {
+		ks[key] = true
+	}
+	for _, m := range models {
+		if (len(m.Id) > 0 && ks[m.Id]) || (len(m.Id) == 0 && ks[m.Code]) {
+			result = append(result, m)
+		}
+	}
+	return result
+}
@@ -0,0 +1,86 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileLoader(t *testing.T) {
+	fsys := fstest.MapFS{
+		"codes.json":         {Data: []byte(`{"gender":[{"code":"F","name":"Female"},{"code":"M","name":"Male"}],"status":[{"code":"A","name":"Active"}]}`)},
+		"list.json":          {Data: []byte(`[{"code":"A","name":"Active"}]`)},
+		"masters/gender.yml": {Data: []byte("- code: F\n  name: Female\n- code: M\n  name: Male\n")},
+		"masters/title.json": {Data: []byte(`[{"code":"MR","name":"Mr"}]`)},
+	}
+	tests := []struct {
+		name   string
+		path   string
+		master string
+		codes  []string
+		err    error
+	}{
+		{"masters", "codes.json", "gender", []string{"F", "M"}, nil},
+		{"unknown master", "codes.json", "title", nil, ErrUnknownMaster},
+		{"codes without masters", "list.json", "status", []string{"A"}, nil},
+		{"yaml file of a directory", "masters", "gender", []string{"F", "M"}, nil},
+		{"json file of a directory", "masters", "title", []string{"MR"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, err := NewFileLoader(fsys, tt.path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			models, err := l.Load(context.Background(), tt.master)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Load(%q) error = %v, want %v", tt.master, err, tt.err)
+			}
+			if codes := getCodes(models); !equalStrings(codes, tt.codes) {
+				t.Errorf("Load(%q) = %v, want %v", tt.master, codes, tt.codes)
+			}
+		})
+	}
+}
+
+func TestFileQuery(t *testing.T) {
+	fsys := fstest.MapFS{
+		"codes.json": {Data: []byte(`{"b":[{"code":"YB","name":"York B"}],"a":[{"code":"YA","name":"York A"}],"c":[{"code":"Y","name":"Other"}]}`)},
+	}
+	q, err := NewFileQuery(fsys, "codes.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	models, err := q.Query(context.Background(), "Y", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(models); !equalStrings(codes, []string{"YA", "YB"}) {
+		t.Errorf("Query = %v, want the codes of the sorted masters", codes)
+	}
+	q.Rank = true
+	models, _ = q.Query(context.Background(), "Y", 2)
+	if codes := getCodes(models); !equalStrings(codes, []string{"Y", "YA"}) {
+		t.Errorf("Query with rank = %v, want the exact code first", codes)
+	}
+}
+
+func getCodes(models []Model) []string {
+	codes := make([]string, 0)
+	for _, m := range models {
+		codes = append(codes, m.Code)
+	}
+	return codes
+}
+func equalStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
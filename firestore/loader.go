@@ -0,0 +1,217 @@
+package firestore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	co "github.com/core-go/code"
+)
+
+const inSize = 30
+
+type Loader struct {
+	Client     *firestore.Client
+	Collection string
+	Config     co.StructureConfig
+	fields     map[string]int
+}
+
+func NewCodeLoader(client *firestore.Client, collection string, config co.StructureConfig) *Loader {
+	return &Loader{Client: client, Collection: collection, Config: config, fields: getFields(config)}
+}
+func (l Loader) Load(ctx context.Context, master string) ([]co.Model, error) {
+	var q firestore.Query
+	if len(l.Collection) > 0 {
+		q = l.Client.Collection(l.Collection).Query
+		if len(l.Config.Master) > 0 {
+			q = q.Where(l.Config.Master, "==", master)
+		}
+	} else {
+		q = l.Client.Collection(master).Query
+	}
+	if len(l.Config.Status) > 0 && l.Config.Active != nil {
+		q = q.Where(l.Config.Status, "==", l.Config.Active)
+	}
+	if len(l.Config.Sequence) > 0 {
+		q = q.OrderBy(l.Config.Sequence, firestore.Asc)
+	}
+	docs, err := q.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, co.ClassifyError(err)
+	}
+	return toModels(docs, l.fields)
+}
+
+type Query struct {
+	Client     *firestore.Client
+	Collection string
+	Field      string
+	Key        string
+	Config     co.StructureConfig
+	Match      string
+	fields     map[string]int
+}
+
+func NewQuery(client *firestore.Client, collection string, config co.StructureConfig, options ...string) *Query {
+	var field, key string
+	if len(options) > 0 && len(options[0]) > 0 {
+		field = options[0]
+	} else if len(config.Name) > 0 {
+		field = config.Name
+	} else {
+		field = "name"
+	}
+	if len(options) > 1 {
+		key = options[1]
+	}
+	return &Query{Client: client, Collection: collection, Field: field, Key: key, Config: config, Match: co.MatchPrefix, fields: getFields(config)}
+}
+func (q Query) Query(ctx context.Context, key string, max int64) ([]co.Model, error) {
+	if max <= 0 {
+		max = 20
+	}
+	match, err := co.ResolveMatch(ctx, q.Match)
+	if err != nil {
+		return nil, err
+	}
+	var query firestore.Query
+	switch match {
+	case co.MatchPrefix:
+		query = q.Client.Collection(q.Collection).Query.
+			Where(q.Field, ">=", key).
+			Where(q.Field, "<", key+"\uf8ff").
+			OrderBy(q.Field, firestore.Asc).
+			Limit(int(max))
+	case co.MatchExact:
+		query = q.Client.Collection(q.Collection).Query.
+			Where(q.Field, "==", key).
+			Limit(int(max))
+	default:
+		// firestore has no operator to match a part of a string
+		return nil, &co.KindError{Kind: co.ErrInvalidInput, Err: fmt.Errorf("match mode '%s' is not supported by firestore", match)}
+	}
+	if len(q.Config.Status) > 0 && q.Config.Active != nil {
+		query = query.Where(q.Config.Status, "==", q.Config.Active)
+	}
+	docs, err := query.Documents(ctx).GetAll()
+	if err != nil {
+		return nil, co.ClassifyError(err)
+	}
+	models, err := toModels(docs, q.fields)
+	if err != nil {
+		return nil, err
+	}
+	if len(q.Config.Sequence) > 0 {
+		sort.SliceStable(models, func(i, j int) bool {
+			return models[i].Sequence < models[j].Sequence
+		})
+	}
+	return models, nil
+}
+func (q Query) Load(ctx context.Context, keys []string) ([]co.Model, error) {
+	collection := q.Client.Collection(q.Collection)
+	docs := make([]*firestore.DocumentSnapshot, 0)
+	if len(q.Key) == 0 {
+		refs := make([]*firestore.DocumentRef, 0)
+		for _, key := range keys {
+			refs = append(refs, collection.Doc(key))
+		}
+		if len(refs) > 0 {
+			snapshots, err := q.Client.GetAll(ctx, refs)
+			if err != nil {
+				return nil, co.ClassifyError(err)
+			}
+			for _, doc := range snapshots {
+				if doc.Exists() {
+					docs = append(docs, doc)
+				}
+			}
+		}
+	} else {
+		for i := 0; i < len(keys); i += inSize {
+			end := i + inSize
+			if end > len(keys) {
+				end = len(keys)
+			}
+			snapshots, err := collection.Where(q.Key, "in", keys[i:end]).Documents(ctx).GetAll()
+			if err != nil {
+				return nil, co.ClassifyError(err)
+			}
+			docs = append(docs, snapshots...)
+		}
+	}
+	if len(q.Config.Status) > 0 && q.Config.Active != nil {
+		active := fmt.Sprint(q.Config.Active)
+		status := make([]*firestore.DocumentSnapshot, 0)
+		for _, doc := range docs {
+			if v, ok := doc.Data()[q.Config.Status]; ok && fmt.Sprint(v) == active {
+				status = append(status, doc)
+			}
+		}
+		docs = status
+	}
+	return toModels(docs, q.fields)
+}
+
+// getFields maps the document fields to the fields of co.Model. When a field is not configured, the firestore tag is used.
+func getFields(c co.StructureConfig) map[string]int {
+	configured := map[string]string{"id": c.Id, "code": c.Code, "value": c.Value, "name": c.Name, "text": c.Text, "parent": c.Parent, "sequence": c.Sequence}
+	modelType := reflect.TypeOf(co.Model{})
+	fields := make(map[string]int)
+	for i := 0; i < modelType.NumField(); i++ {
+		tag := modelType.Field(i).Tag.Get("firestore")
+		name := strings.Split(tag, ",")[0]
+		if len(name) == 0 || name == "-" {
+			continue
+		}
+		if field, ok := configured[name]; ok && len(field) > 0 {
+			fields[field] = i
+		} else {
+			fields[name] = i
+		}
+	}
+	return fields
+}
+func toModels(docs []*firestore.DocumentSnapshot, fields map[string]int) ([]co.Model, error) {
+	models := make([]co.Model, 0)
+	for _, doc := range docs {
+		var model co.Model
+		data := doc.Data()
+		v := reflect.ValueOf(&model).Elem()
+		for name, i := range fields {
+			x, ok := data[name]
+			if !ok || x == nil {
+				continue
+			}
+			f := v.Field(i)
+			switch f.Kind() {
+			case reflect.String:
+				f.SetString(fmt.Sprint(x))
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				switch n := x.(type) {
+				case int64:
+					f.SetInt(n)
+				case float64:
+					f.SetInt(int64(n))
+				default:
+					s := fmt.Sprint(x)
+					m, err := strconv.ParseInt(s, 10, 64)
+					if err != nil {
+						return nil, &co.ScanError{Row: len(models) + 1, Err: fmt.Errorf("cannot convert field %s with value '%s' of document %s to %s", name, s, doc.Ref.ID, f.Kind())}
+					}
+					f.SetInt(m)
+				}
+			}
+		}
+		if len(model.Id) == 0 && doc.Ref != nil {
+			model.Id = doc.Ref.ID
+		}
+		models = append(models, model)
+	}
+	return models, nil
+}
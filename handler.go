@@ -3,23 +3,30 @@ package code
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
 	"net/http"
+	"reflect"
 	"strconv"
 	"strings"
 )
 
-const internalServerError = "Internal Server Error"
-
 type HandlerConfig struct {
-	Master   *bool  `yaml:"master" mapstructure:"master" json:"master,omitempty" gorm:"column:master" bson:"master,omitempty" dynamodbav:"master,omitempty" firestore:"master,omitempty"`
-	Id       string `yaml:"id" mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
-	Name     string `yaml:"name" mapstructure:"name" json:"name,omitempty" gorm:"column:name" bson:"name,omitempty" dynamodbav:"name,omitempty" firestore:"name,omitempty"`
-	Resource string `yaml:"resource" mapstructure:"resource" json:"resource,omitempty" gorm:"column:resource" bson:"resource,omitempty" dynamodbav:"resource,omitempty" firestore:"resource,omitempty"`
-	Action   string `yaml:"action" mapstructure:"action" json:"action,omitempty" gorm:"column:action" bson:"action,omitempty" dynamodbav:"action,omitempty" firestore:"action,omitempty"`
-}
-type Handler struct {
-	Codes          func(ctx context.Context, master string) ([]Model, error)
+	Master        *bool    `yaml:"master" mapstructure:"master" json:"master,omitempty" gorm:"column:master" bson:"master,omitempty" dynamodbav:"master,omitempty" firestore:"master,omitempty"`
+	Id            string   `yaml:"id" mapstructure:"id" json:"id,omitempty" gorm:"column:id" bson:"id,omitempty" dynamodbav:"id,omitempty" firestore:"id,omitempty"`
+	Name          string   `yaml:"name" mapstructure:"name" json:"name,omitempty" gorm:"column:name" bson:"name,omitempty" dynamodbav:"name,omitempty" firestore:"name,omitempty"`
+	Resource      string   `yaml:"resource" mapstructure:"resource" json:"resource,omitempty" gorm:"column:resource" bson:"resource,omitempty" dynamodbav:"resource,omitempty" firestore:"resource,omitempty"`
+	Action        string   `yaml:"action" mapstructure:"action" json:"action,omitempty" gorm:"column:action" bson:"action,omitempty" dynamodbav:"action,omitempty" firestore:"action,omitempty"`
+	Locales       []string `yaml:"locales" mapstructure:"locales" json:"locales,omitempty" gorm:"column:locales" bson:"locales,omitempty" dynamodbav:"locales,omitempty" firestore:"locales,omitempty"`
+	DefaultLocale string   `yaml:"default_locale" mapstructure:"default_locale" json:"defaultLocale,omitempty" gorm:"column:defaultlocale" bson:"defaultLocale,omitempty" dynamodbav:"defaultLocale,omitempty" firestore:"defaultLocale,omitempty"`
+	LocaleParam   string   `yaml:"locale_param" mapstructure:"locale_param" json:"localeParam,omitempty" gorm:"column:localeparam" bson:"localeParam,omitempty" dynamodbav:"localeParam,omitempty" firestore:"localeParam,omitempty"`
+	Tree          string   `yaml:"tree" mapstructure:"tree" json:"tree,omitempty" gorm:"column:tree" bson:"tree,omitempty" dynamodbav:"tree,omitempty" firestore:"tree,omitempty"`
+	TreeParam     string   `yaml:"tree_param" mapstructure:"tree_param" json:"treeParam,omitempty" gorm:"column:treeparam" bson:"treeParam,omitempty" dynamodbav:"treeParam,omitempty" firestore:"treeParam,omitempty"`
+	AsOfParam     string   `yaml:"as_of_param" mapstructure:"as_of_param" json:"asOfParam,omitempty" gorm:"column:asofparam" bson:"asOfParam,omitempty" dynamodbav:"asOfParam,omitempty" firestore:"asOfParam,omitempty"`
+}
+type Handler = GenericHandler[Model]
+type GenericHandler[T any] struct {
+	Codes          func(ctx context.Context, master string) ([]T, error)
 	RequiredMaster bool
 	Error          func(context.Context, string, ...map[string]interface{})
 	Log            func(ctx context.Context, resource string, action string, success bool, desc string) error
@@ -27,16 +34,30 @@ type Handler struct {
 	Action         string
 	Id             string
 	Name           string
+	Locales        []string
+	DefaultLocale  string
+	LocaleParam    string
+	Tree           string
+	TreeParam      string
+	AsOfParam      string
+	Many           func(ctx context.Context, masters []string) (map[string][]T, error)
+	MastersParam   string
 }
 
 func NewDefaultCodeHandler(load func(ctx context.Context, master string) ([]Model, error), logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *Handler {
+	return NewDefaultGenericCodeHandler[Model](load, logError, options...)
+}
+func NewDefaultGenericCodeHandler[T any](load func(ctx context.Context, master string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *GenericHandler[T] {
 	var writeLog func(context.Context, string, string, bool, string) error
 	if len(options) >= 1 {
 		writeLog = options[0]
 	}
-	return NewCodeHandlerWithLog(load, logError, true, writeLog, "", "")
+	return NewGenericCodeHandlerWithLog[T](load, logError, true, writeLog, "", "")
 }
 func NewCodeHandlerByConfig(load func(ctx context.Context, master string) ([]Model, error), c HandlerConfig, logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *Handler {
+	return NewGenericCodeHandlerByConfig[Model](load, c, logError, options...)
+}
+func NewGenericCodeHandlerByConfig[T any](load func(ctx context.Context, master string) ([]T, error), c HandlerConfig, logError func(context.Context, string, ...map[string]interface{}), options ...func(context.Context, string, string, bool, string) error) *GenericHandler[T] {
 	var requireMaster bool
 	if c.Master != nil {
 		requireMaster = *c.Master
@@ -47,19 +68,43 @@ func NewCodeHandlerByConfig(load func(ctx context.Context, master string) ([]Mod
 	if len(options) >= 1 {
 		writeLog = options[0]
 	}
-	h := NewCodeHandlerWithLog(load, logError, requireMaster, writeLog, c.Resource, c.Action)
+	h := NewGenericCodeHandlerWithLog[T](load, logError, requireMaster, writeLog, c.Resource, c.Action)
 	h.Id = c.Id
 	h.Name = c.Name
+	h.Locales = c.Locales
+	h.DefaultLocale = c.DefaultLocale
+	if len(c.LocaleParam) > 0 {
+		h.LocaleParam = c.LocaleParam
+	} else {
+		h.LocaleParam = "locale"
+	}
+	h.Tree = c.Tree
+	if len(c.TreeParam) > 0 {
+		h.TreeParam = c.TreeParam
+	} else {
+		h.TreeParam = "tree"
+	}
+	if len(c.AsOfParam) > 0 {
+		h.AsOfParam = c.AsOfParam
+	} else {
+		h.AsOfParam = "asOf"
+	}
 	return h
 }
 func NewCodeHandler(load func(ctx context.Context, master string) ([]Model, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, options ...func(context.Context, string, string, bool, string) error) *Handler {
+	return NewGenericCodeHandler[Model](load, logError, requiredMaster, options...)
+}
+func NewGenericCodeHandler[T any](load func(ctx context.Context, master string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, options ...func(context.Context, string, string, bool, string) error) *GenericHandler[T] {
 	var writeLog func(context.Context, string, string, bool, string) error
 	if len(options) >= 1 {
 		writeLog = options[0]
 	}
-	return NewCodeHandlerWithLog(load, logError, requiredMaster, writeLog, "", "")
+	return NewGenericCodeHandlerWithLog[T](load, logError, requiredMaster, writeLog, "", "")
 }
 func NewCodeHandlerWithLog(load func(ctx context.Context, master string) ([]Model, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, writeLog func(context.Context, string, string, bool, string) error, options ...string) *Handler {
+	return NewGenericCodeHandlerWithLog[Model](load, logError, requiredMaster, writeLog, options...)
+}
+func NewGenericCodeHandlerWithLog[T any](load func(ctx context.Context, master string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), requiredMaster bool, writeLog func(context.Context, string, string, bool, string) error, options ...string) *GenericHandler[T] {
 	var resource, action string
 	if len(options) >= 1 && len(options[0]) > 0 {
 		resource = options[0]
@@ -71,16 +116,16 @@ func NewCodeHandlerWithLog(load func(ctx context.Context, master string) ([]Mode
 	} else {
 		action = "load"
 	}
-	h := Handler{Codes: load, Resource: resource, Action: action, RequiredMaster: requiredMaster, Log: writeLog, Error: logError}
+	h := GenericHandler[T]{Codes: load, Resource: resource, Action: action, RequiredMaster: requiredMaster, Log: writeLog, Error: logError, MastersParam: "masters"}
 	return &h
 }
-func (h *Handler) Load(w http.ResponseWriter, r *http.Request) {
+func (h *GenericHandler[T]) Load(w http.ResponseWriter, r *http.Request) {
 	code := ""
 	if h.RequiredMaster {
 		if r.Method == "GET" {
-			i := strings.LastIndex(r.RequestURI, "/")
+			i := strings.LastIndex(r.URL.Path, "/")
 			if i >= 0 {
-				code = r.RequestURI[i+1:]
+				code = r.URL.Path[i+1:]
 			}
 		} else {
 			b, er1 := ioutil.ReadAll(r.Body)
@@ -91,35 +136,108 @@ func (h *Handler) Load(w http.ResponseWriter, r *http.Request) {
 			code = strings.Trim(string(b), " ")
 		}
 	}
-	result, er4 := h.Codes(r.Context(), code)
+	mode, er2 := GetTreeMode(r, h.TreeParam, h.Tree)
+	if er2 != nil {
+		http.Error(w, er2.Error(), http.StatusBadRequest)
+		return
+	}
+	asOf, er3 := GetRequestAsOf(r, h.AsOfParam)
+	if er3 != nil {
+		http.Error(w, er3.Error(), http.StatusBadRequest)
+		return
+	}
+	lc := setLocale(w, r, h.LocaleParam, h.Locales, h.DefaultLocale)
+	if asOf != nil {
+		lc = WithAsOf(lc, *asOf)
+	}
+	ctx, stale := WithStaleFlag(lc)
+	result, er4 := h.Codes(ctx, code)
 	if er4 != nil {
-		respondError(w, r, http.StatusInternalServerError, internalServerError, h.Error, h.Resource, h.Action, er4, h.Log)
+		respondError(w, r, h.Error, h.Resource, h.Action, er4, h.Log)
 	} else {
-		if len(h.Id) == 0 && len(h.Name) == 0 {
+		if *stale {
+			w.Header().Set(StaleHeader, "true")
+		}
+		if len(mode) > 0 {
+			tree, er5 := ToTree(result, mode)
+			if er5 != nil {
+				respondError(w, r, h.Error, h.Resource, h.Action, er5, h.Log)
+			} else {
+				succeed(w, r, http.StatusOK, tree, h.Log, h.Resource, h.Action)
+			}
+		} else if len(h.Id) == 0 && len(h.Name) == 0 {
 			succeed(w, r, http.StatusOK, result, h.Log, h.Resource, h.Action)
 		} else {
-			rs := make([]map[string]string, 0)
-			for _, r := range result {
-				m := make(map[string]string)
-				m[h.Id] = r.Id
-				m[h.Name] = r.Name
-				rs = append(rs, m)
-			}
+			rs := MapIdName(result, h.Id, h.Name)
 			succeed(w, r, http.StatusOK, rs, h.Log, h.Resource, h.Action)
 		}
 	}
 }
+func (h *GenericHandler[T]) LoadMany(w http.ResponseWriter, r *http.Request) {
+	masters := make([]string, 0)
+	if r.Method == http.MethodGet {
+		if q := r.URL.Query().Get(h.MastersParam); len(q) > 0 {
+			masters = strings.Split(q, ",")
+		}
+	} else {
+		er1 := json.NewDecoder(r.Body).Decode(&masters)
+		if er1 != nil {
+			http.Error(w, er1.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	mode, er2 := GetTreeMode(r, h.TreeParam, h.Tree)
+	if er2 != nil {
+		http.Error(w, er2.Error(), http.StatusBadRequest)
+		return
+	}
+	asOf, er3 := GetRequestAsOf(r, h.AsOfParam)
+	if er3 != nil {
+		http.Error(w, er3.Error(), http.StatusBadRequest)
+		return
+	}
+	lc := setLocale(w, r, h.LocaleParam, h.Locales, h.DefaultLocale)
+	if asOf != nil {
+		lc = WithAsOf(lc, *asOf)
+	}
+	ctx, stale := WithStaleFlag(lc)
+	result, er4 := LoadMasters(ctx, masters, h.Many, h.Codes)
+	if er4 != nil {
+		respondError(w, r, h.Error, h.Resource, h.Action, er4, h.Log)
+		return
+	}
+	if *stale {
+		w.Header().Set(StaleHeader, "true")
+	}
+	rs, er5 := FormatCodes(result, mode, h.Id, h.Name)
+	if er5 != nil {
+		respondError(w, r, h.Error, h.Resource, h.Action, er5, h.Log)
+	} else {
+		succeed(w, r, http.StatusOK, rs, h.Log, h.Resource, h.Action)
+	}
+}
 
-type QueryHandler struct {
-	Get      func(ctx context.Context, key string, max int64) ([]Model, error)
-	Select   func(ctx context.Context, key []string) ([]Model, error)
-	LogError func(context.Context, string, ...map[string]interface{})
-	Keyword  string
-	Max      string
-	Q        string
+type QueryHandler = GenericQueryHandler[Model]
+type GenericQueryHandler[T any] struct {
+	Get           func(ctx context.Context, key string, max int64) ([]T, error)
+	Select        func(ctx context.Context, key []string) ([]T, error)
+	LogError      func(context.Context, string, ...map[string]interface{})
+	Keyword       string
+	Max           string
+	Q             string
+	Offset        string
+	Page          string
+	Match         string
+	GetPage       func(ctx context.Context, key string, max int64, offset int64) ([]T, error)
+	Locales       []string
+	DefaultLocale string
+	LocaleParam   string
 }
 
 func NewQueryHandler(load func(ctx context.Context, key string, max int64) ([]Model, error), getData func(ctx context.Context, key []string) ([]Model, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *QueryHandler {
+	return NewGenericQueryHandler[Model](load, getData, logError, opts...)
+}
+func NewGenericQueryHandler[T any](load func(ctx context.Context, key string, max int64) ([]T, error), getData func(ctx context.Context, key []string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *GenericQueryHandler[T] {
 	q := "q"
 	if len(opts) > 0 && len(opts[0]) > 0 {
 		q = opts[0]
@@ -132,9 +250,34 @@ func NewQueryHandler(load func(ctx context.Context, key string, max int64) ([]Mo
 	if len(opts) > 2 && len(opts[2]) > 0 {
 		max = opts[2]
 	}
-	return &QueryHandler{load, getData, logError, keyword, max, q}
+	offset := "offset"
+	if len(opts) > 3 && len(opts[3]) > 0 {
+		offset = opts[3]
+	}
+	page := "page"
+	if len(opts) > 4 && len(opts[4]) > 0 {
+		page = opts[4]
+	}
+	match := "match"
+	if len(opts) > 5 && len(opts[5]) > 0 {
+		match = opts[5]
+	}
+	return &GenericQueryHandler[T]{Get: load, Select: getData, LogError: logError, Keyword: keyword, Max: max, Q: q, Offset: offset, Page: page, Match: match}
+}
+
+// NewPageQueryHandler queries the pages with getPage, such as Query.QueryPage, so that the next pages are loaded by offset.
+func NewPageQueryHandler(getPage func(ctx context.Context, key string, max int64, offset int64) ([]Model, error), getData func(ctx context.Context, key []string) ([]Model, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *QueryHandler {
+	return NewGenericPageQueryHandler[Model](getPage, getData, logError, opts...)
+}
+func NewGenericPageQueryHandler[T any](getPage func(ctx context.Context, key string, max int64, offset int64) ([]T, error), getData func(ctx context.Context, key []string) ([]T, error), logError func(context.Context, string, ...map[string]interface{}), opts ...string) *GenericQueryHandler[T] {
+	load := func(ctx context.Context, key string, max int64) ([]T, error) {
+		return getPage(ctx, key, max, 0)
+	}
+	h := NewGenericQueryHandler[T](load, getData, logError, opts...)
+	h.GetPage = getPage
+	return h
 }
-func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
+func (h *GenericQueryHandler[T]) Query(w http.ResponseWriter, r *http.Request) {
 	ps := r.URL.Query()
 	keyword := ps.Get(h.Keyword)
 	if len(keyword) == 0 {
@@ -149,11 +292,21 @@ func (h *QueryHandler) Query(w http.ResponseWriter, r *http.Request) {
 		if i < 0 {
 			i = 20
 		}
-		vs, err := h.Get(r.Context(), keyword, i)
+		offset := GetOffset(r, h.Offset, h.Page, i)
+		var vs []T
+		// the locale is passed to the query functions, without Content-Language, as not all the queries translate the names
+		lc, _ := getLocale(w, r, h.LocaleParam, h.Locales, h.DefaultLocale)
+		match, err := GetRequestMatch(r, h.Match)
+		if err == nil {
+			if len(match) > 0 {
+				lc = WithMatch(lc, match)
+			}
+			vs, err = QueryPage(lc, keyword, i, offset, h.GetPage, h.Get)
+		}
 		respondModel(w, r, vs, err, h.LogError, nil)
 	}
 }
-func (h *QueryHandler) Load(w http.ResponseWriter, r *http.Request) {
+func (h *GenericQueryHandler[T]) Load(w http.ResponseWriter, r *http.Request) {
 	var req = make([]string, 0)
 	method := r.Method
 	if method == http.MethodGet {
@@ -171,7 +324,8 @@ func (h *QueryHandler) Load(w http.ResponseWriter, r *http.Request) {
 	if len(req) == 0 {
 		respondModel(w, r, req, nil, h.LogError, nil)
 	} else {
-		models, err := h.Select(r.Context(), req)
+		lc, _ := getLocale(w, r, h.LocaleParam, h.Locales, h.DefaultLocale)
+		models, err := h.Select(lc, req)
 		respondModel(w, r, models, err, h.LogError, nil)
 	}
 }
@@ -186,11 +340,12 @@ func respond(w http.ResponseWriter, r *http.Request, code int, result interface{
 		writeLog(r.Context(), resource, action, success, desc)
 	}
 }
-func respondError(w http.ResponseWriter, r *http.Request, code int, result interface{}, logError func(context.Context, string, ...map[string]interface{}), resource string, action string, err error, writeLog func(context.Context, string, string, bool, string) error) {
-	if logError != nil {
+func respondError(w http.ResponseWriter, r *http.Request, logError func(context.Context, string, ...map[string]interface{}), resource string, action string, err error, writeLog func(context.Context, string, string, bool, string) error) {
+	status, message := GetErrorStatus(err)
+	if logError != nil && (status >= http.StatusInternalServerError || message != err.Error()) {
 		logError(r.Context(), err.Error())
 	}
-	respond(w, r, code, result, writeLog, resource, action, false, err.Error())
+	respond(w, r, status, message, writeLog, resource, action, false, err.Error())
 }
 func succeed(w http.ResponseWriter, r *http.Request, code int, result interface{}, writeLog func(context.Context, string, string, bool, string) error, resource string, action string) {
 	respond(w, r, code, result, writeLog, resource, action, true, "")
@@ -205,7 +360,7 @@ func respondModel(w http.ResponseWriter, r *http.Request, model interface{}, err
 		action = options[1]
 	}
 	if err != nil {
-		respondAndLog(w, r, http.StatusInternalServerError, internalServerError, err, logError, writeLog, resource, action)
+		respondAndLog(w, r, http.StatusInternalServerError, nil, err, logError, writeLog, resource, action)
 	} else {
 		if model == nil {
 			returnAndLog(w, r, http.StatusNotFound, model, writeLog, false, resource, action, "Not found")
@@ -223,11 +378,14 @@ func respondAndLog(w http.ResponseWriter, r *http.Request, code int, result inte
 		action = options[1]
 	}
 	if err != nil {
+		status, message := GetErrorStatus(err)
 		if logError != nil {
-			logError(r.Context(), err.Error())
-			return returnAndLog(w, r, http.StatusInternalServerError, internalServerError, writeLog, false, resource, action, err.Error())
+			if status >= http.StatusInternalServerError || message != err.Error() {
+				logError(r.Context(), err.Error())
+			}
+			return returnAndLog(w, r, status, message, writeLog, false, resource, action, err.Error())
 		} else {
-			return returnAndLog(w, r, http.StatusInternalServerError, err.Error(), writeLog, false, resource, action, err.Error())
+			return returnAndLog(w, r, status, err.Error(), writeLog, false, resource, action, err.Error())
 		}
 	} else {
 		return returnAndLog(w, r, code, result, writeLog, true, resource, action, "")
@@ -242,3 +400,117 @@ func returnAndLog(w http.ResponseWriter, r *http.Request, code int, result inter
 	}
 	return err
 }
+func MapIdName[T any](result []T, id string, name string) []map[string]string {
+	rs := make([]map[string]string, 0)
+	if models, ok := interface{}(result).([]Model); ok {
+		for _, r := range models {
+			m := make(map[string]string)
+			m[id] = r.Id
+			m[name] = r.Name
+			rs = append(rs, m)
+		}
+		return rs
+	}
+	modelType := reflect.TypeOf((*T)(nil)).Elem()
+	if modelType.Kind() == reflect.Ptr {
+		modelType = modelType.Elem()
+	}
+	fields, _ := getColumnIndexes(modelType, nil)
+	idIndex, hasId := fields["id"]
+	nameIndex, hasName := fields["name"]
+	for _, r := range result {
+		v := reflect.Indirect(reflect.ValueOf(r))
+		m := make(map[string]string)
+		if hasId {
+			m[id] = fmt.Sprint(v.Field(idIndex).Interface())
+		}
+		if hasName {
+			m[name] = fmt.Sprint(v.Field(nameIndex).Interface())
+		}
+		rs = append(rs, m)
+	}
+	return rs
+}
+// LoadMasters loads the codes of the masters in one round trip if many is set, else one master at a time.
+func LoadMasters[T any](ctx context.Context, masters []string, many func(ctx context.Context, masters []string) (map[string][]T, error), load func(ctx context.Context, master string) ([]T, error)) (map[string][]T, error) {
+	if many != nil {
+		return many(ctx, masters)
+	}
+	codes := make(map[string][]T)
+	for _, master := range masters {
+		if _, ok := codes[master]; ok {
+			continue
+		}
+		models, err := load(ctx, master)
+		if err != nil {
+			return nil, err
+		}
+		codes[master] = models
+	}
+	return codes, nil
+}
+func FormatCodes[T any](codes map[string][]T, mode string, id string, name string) (map[string]interface{}, error) {
+	rs := make(map[string]interface{})
+	for master, models := range codes {
+		if len(mode) > 0 {
+			tree, err := ToTree(models, mode)
+			if err != nil {
+				return nil, err
+			}
+			rs[master] = tree
+		} else if len(id) == 0 && len(name) == 0 {
+			rs[master] = models
+		} else {
+			rs[master] = MapIdName(models, id, name)
+		}
+	}
+	return rs, nil
+}
+// GetOffset returns the value of the offset parameter, else the offset of the page parameter, where the first page is 1.
+func GetOffset(r *http.Request, offset string, page string, max int64) int64 {
+	ps := r.URL.Query()
+	if len(offset) > 0 {
+		if i, err := strconv.ParseInt(ps.Get(offset), 10, 64); err == nil && i > 0 {
+			return i
+		}
+	}
+	if len(page) > 0 {
+		if i, err := strconv.ParseInt(ps.Get(page), 10, 64); err == nil && i > 1 {
+			return (i - 1) * max
+		}
+	}
+	return 0
+}
+
+// QueryPage queries with getPage if it is set, else queries offset + max codes with get and skips the offset.
+func QueryPage[T any](ctx context.Context, key string, max int64, offset int64, getPage func(ctx context.Context, key string, max int64, offset int64) ([]T, error), get func(ctx context.Context, key string, max int64) ([]T, error)) ([]T, error) {
+	if getPage != nil {
+		return getPage(ctx, key, max, offset)
+	}
+	if offset <= 0 {
+		return get(ctx, key, max)
+	}
+	models, err := get(ctx, key, offset+max)
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(models)) <= offset {
+		return make([]T, 0), nil
+	}
+	return models[offset:], nil
+}
+func setLocale(w http.ResponseWriter, r *http.Request, param string, locales []string, defaultLocale string) context.Context {
+	ctx, locale := getLocale(w, r, param, locales, defaultLocale)
+	if len(locale) > 0 {
+		w.Header().Set(LocaleHeader, locale)
+	}
+	return ctx
+}
+
+// getLocale returns the context with the locale of the request. The response varies with Accept-Language if the locales are configured.
+func getLocale(w http.ResponseWriter, r *http.Request, param string, locales []string, defaultLocale string) (context.Context, string) {
+	if len(locales) > 0 {
+		w.Header().Add("Vary", "Accept-Language")
+	}
+	return WithRequestLocale(r, param, locales, defaultLocale)
+}
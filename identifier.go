@@ -0,0 +1,160 @@
+package code
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	identifierPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$#]*$`)
+	localePattern     = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+)
+
+// QuoteIdentifier validates the identifier, which can be qualified by a schema, such as sales.codes.
+// The unquoted parts are kept as is, so that the case folding of the database applies; the parts quoted with double quotes, backticks or brackets are quoted by the dialect.
+func QuoteIdentifier(dialect Dialect, name string) (string, error) {
+	parts, ok := splitIdentifier(name)
+	if !ok {
+		return "", fmt.Errorf("'%s' is not a valid identifier", name)
+	}
+	for i, part := range parts {
+		if identifierPattern.MatchString(part) {
+			continue
+		}
+		inner, ok := unquote(part)
+		if !ok {
+			return "", fmt.Errorf("'%s' is not a valid identifier", name)
+		}
+		parts[i] = dialect.Quote(inner)
+	}
+	return strings.Join(parts, "."), nil
+}
+
+// columnName returns the name of the column in the result set, the last part of the identifier without quotes.
+func columnName(identifier string) string {
+	parts, ok := splitIdentifier(identifier)
+	if !ok {
+		return identifier
+	}
+	name := parts[len(parts)-1]
+	if inner, ok := unquote(name); ok {
+		return inner
+	}
+	return name
+}
+func splitIdentifier(name string) ([]string, bool) {
+	parts := make([]string, 0)
+	start := 0
+	var closing byte
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if closing != 0 {
+			if c == closing {
+				closing = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '`':
+			closing = c
+		case '[':
+			closing = ']'
+		case '.':
+			parts = append(parts, name[start:i])
+			start = i + 1
+		}
+	}
+	if closing != 0 {
+		return nil, false
+	}
+	parts = append(parts, name[start:])
+	for _, part := range parts {
+		if len(part) == 0 {
+			return nil, false
+		}
+	}
+	return parts, true
+}
+func unquote(part string) (string, bool) {
+	if len(part) < 3 {
+		return "", false
+	}
+	first, last := part[0], part[len(part)-1]
+	if !((first == '"' && last == '"') || (first == '`' && last == '`') || (first == '[' && last == ']')) {
+		return "", false
+	}
+	inner := part[1 : len(part)-1]
+	for _, c := range inner {
+		if c < ' ' || c == '"' || c == '`' || c == '[' || c == ']' {
+			return "", false
+		}
+	}
+	return inner, true
+}
+
+// quoteOrder validates an order by clause, such as "sequence" or "level, sequence desc".
+func quoteOrder(dialect Dialect, order string) (string, error) {
+	items := make([]string, 0)
+	for _, item := range strings.Split(order, ",") {
+		item = strings.TrimSpace(item)
+		direction := ""
+		if i := strings.LastIndexAny(item, " \t"); i >= 0 {
+			d := strings.ToLower(item[i+1:])
+			if d != "asc" && d != "desc" {
+				return "", fmt.Errorf("'%s' is not a valid order", order)
+			}
+			direction = " " + d
+			item = strings.TrimSpace(item[:i])
+		}
+		column, err := QuoteIdentifier(dialect, item)
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a valid order", order)
+		}
+		items = append(items, column+direction)
+	}
+	return strings.Join(items, ", "), nil
+}
+
+// quoteConfig returns the config with the quoted identifiers, or an error which names the invalid config key.
+func quoteConfig(dialect Dialect, c StructureConfig) (StructureConfig, error) {
+	q := c
+	columns := map[string]*string{"master": &q.Master, "id": &q.Id, "code": &q.Code, "text": &q.Text, "name": &q.Name, "value": &q.Value, "status": &q.Status, "parent": &q.Parent, "valid_from": &q.ValidFrom, "valid_to": &q.ValidTo}
+	for _, key := range []string{"master", "id", "code", "text", "name", "value", "status", "parent", "valid_from", "valid_to"} {
+		column := columns[key]
+		if len(*column) == 0 {
+			continue
+		}
+		quoted, err := QuoteIdentifier(dialect, *column)
+		if err != nil {
+			return q, fmt.Errorf("invalid config '%s': %s", key, err.Error())
+		}
+		*column = quoted
+	}
+	if len(c.Sequence) > 0 {
+		sequence, err := quoteOrder(dialect, c.Sequence)
+		if err != nil {
+			return q, fmt.Errorf("invalid config 'sequence': %s", err.Error())
+		}
+		q.Sequence = sequence
+	}
+	q.Attributes = make([]string, 0)
+	for _, attribute := range c.Attributes {
+		quoted, err := QuoteIdentifier(dialect, attribute)
+		if err != nil {
+			return q, fmt.Errorf("invalid config 'attributes': %s", err.Error())
+		}
+		q.Attributes = append(q.Attributes, quoted)
+	}
+	if len(c.Locales) > 0 {
+		if !identifierPattern.MatchString(c.Name) {
+			return q, fmt.Errorf("invalid config 'locales': the localized columns require 'name' to be an unquoted column, not '%s'", c.Name)
+		}
+		for _, locale := range c.Locales {
+			if !localePattern.MatchString(locale) {
+				return q, fmt.Errorf("invalid config 'locales': '%s' is not a valid locale", locale)
+			}
+		}
+	}
+	return q, nil
+}
@@ -0,0 +1,329 @@
+package code
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const LocaleHeader = "Content-Language"
+
+type localeKey struct{}
+
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+func GetLocale(ctx context.Context) string {
+	if locale, ok := ctx.Value(localeKey{}).(string); ok {
+		return locale
+	}
+	return ""
+}
+
+// FallbackLocales returns the chain of locales to try, for example fr-CA, fr, then the default locale.
+func FallbackLocales(locale string, defaultLocale string) []string {
+	chain := make([]string, 0)
+	for len(locale) > 0 {
+		chain = append(chain, locale)
+		i := strings.LastIndexAny(locale, "-_")
+		if i < 0 {
+			break
+		}
+		locale = locale[:i]
+	}
+	if len(defaultLocale) > 0 && !containsFold(chain, defaultLocale) {
+		chain = append(chain, defaultLocale)
+	}
+	return chain
+}
+func ParseAcceptLanguage(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+	items := make([]weighted, 0)
+	for _, part := range strings.Split(header, ",") {
+		part = strings.TrimSpace(part)
+		if len(part) == 0 {
+			continue
+		}
+		q := 1.0
+		if i := strings.Index(part, ";"); i >= 0 {
+			params := part[i+1:]
+			part = strings.TrimSpace(part[:i])
+			for _, p := range strings.Split(params, ";") {
+				p = strings.TrimSpace(p)
+				if strings.HasPrefix(p, "q=") {
+					if v, err := strconv.ParseFloat(p[2:], 64); err == nil {
+						q = v
+					}
+				}
+			}
+		}
+		if part == "*" || q <= 0 {
+			continue
+		}
+		items = append(items, weighted{locale: part, q: q})
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].q > items[j].q
+	})
+	locales := make([]string, 0)
+	for _, item := range items {
+		locales = append(locales, item.locale)
+	}
+	return locales
+}
+
+// ResolveLocale returns the first supported locale in the fallback chains of the requested locales.
+func ResolveLocale(requested []string, supported []string, defaultLocale string) string {
+	for _, locale := range requested {
+		for _, l := range FallbackLocales(locale, "") {
+			for _, s := range supported {
+				if strings.EqualFold(s, l) {
+					return s
+				}
+			}
+		}
+	}
+	return defaultLocale
+}
+func GetRequestLocale(r *http.Request, param string, supported []string, defaultLocale string) string {
+	requested := make([]string, 0)
+	if len(param) > 0 {
+		if locale := r.URL.Query().Get(param); len(locale) > 0 {
+			requested = append(requested, locale)
+		}
+	}
+	requested = append(requested, ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+	return ResolveLocale(requested, supported, defaultLocale)
+}
+
+// WithRequestLocale returns the context with the locale of the request, and the locale, if the locales are configured.
+func WithRequestLocale(r *http.Request, param string, locales []string, defaultLocale string) (context.Context, string) {
+	if len(locales) == 0 {
+		return r.Context(), ""
+	}
+	locale := GetRequestLocale(r, param, locales, defaultLocale)
+	if len(locale) == 0 {
+		return r.Context(), ""
+	}
+	return WithLocale(r.Context(), locale), locale
+}
+
+// getLocaleColumn returns the locale-suffixed columns, such as coalesce(name_fr_ca, name_fr, name), for the locale in the context, falling back to the column itself.
+func getLocaleColumn(ctx context.Context, column string, locales []string) string {
+	return localeColumn(column, GetLocale(ctx), locales)
+}
+func localeColumn(column string, locale string, locales []string) string {
+	if len(locales) == 0 || len(locale) == 0 {
+		return column
+	}
+	columns := make([]string, 0)
+	for _, l := range FallbackLocales(locale, "") {
+		if containsFold(locales, l) {
+			columns = append(columns, localeColumnName(column, l))
+		}
+	}
+	if len(columns) == 0 {
+		return column
+	}
+	return fmt.Sprintf("coalesce(%s, %s)", strings.Join(columns, ", "), column)
+}
+func localeColumnName(column string, locale string) string {
+	return column + "_" + strings.ToLower(strings.Replace(locale, "-", "_", -1))
+}
+
+// localizeSelect replaces the name column of the select list, such as c.name or label as name, by the locale-suffixed columns of the locale in the context.
+// The query is kept as is if the name is an expression or a quoted column.
+func localizeSelect(ctx context.Context, query string, locales []string) string {
+	locale := GetLocale(ctx)
+	if len(locales) == 0 || len(locale) == 0 {
+		return query
+	}
+	items, ok := selectItems(tokenize(query))
+	if !ok {
+		return query
+	}
+	for _, item := range items {
+		column := item
+		if n := len(item); n > 1 && item[n-2].text != "." {
+			column = item[:n-1]
+			if len(column) > 1 && strings.EqualFold(column[len(column)-1].text, "as") {
+				column = column[:len(column)-1]
+			}
+		}
+		alias := item[len(item)-1].text
+		if !strings.EqualFold(alias, "name") || !isColumn(column) {
+			continue
+		}
+		last := item[len(item)-1]
+		expr := query[column[0].start : column[len(column)-1].start+len(column[len(column)-1].text)]
+		localized := localeColumn(expr, locale, locales)
+		if localized == expr {
+			return query
+		}
+		return query[:item[0].start] + localized + " as " + alias + query[last.start+len(last.text):]
+	}
+	return query
+}
+
+// isColumn reports whether the tokens are an unquoted column, which can be qualified, such as c.name.
+func isColumn(tokens []token) bool {
+	if len(tokens)%2 == 0 {
+		return false
+	}
+	for k, t := range tokens {
+		if k%2 == 1 && t.text != "." || k%2 == 0 && (!isIdentStart(t.text[0]) || strings.EqualFold(t.text, "as")) {
+			return false
+		}
+	}
+	return true
+}
+
+type LocaleLoader struct {
+	Codes         func(ctx context.Context, master string) ([]Model, error)
+	Translate     func(ctx context.Context, master string, locales []string) (map[string]map[string]Model, error)
+	DefaultLocale string
+}
+
+func NewLocaleLoader(load func(ctx context.Context, master string) ([]Model, error), translate func(ctx context.Context, master string, locales []string) (map[string]map[string]Model, error), defaultLocale string) *LocaleLoader {
+	return &LocaleLoader{Codes: load, Translate: translate, DefaultLocale: defaultLocale}
+}
+func (l LocaleLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	models, err := l.Codes(ctx, master)
+	if err != nil {
+		return models, err
+	}
+	locale := GetLocale(ctx)
+	if len(locale) == 0 || strings.EqualFold(locale, l.DefaultLocale) {
+		return models, nil
+	}
+	chain := make([]string, 0)
+	for _, s := range FallbackLocales(locale, "") {
+		if !strings.EqualFold(s, l.DefaultLocale) {
+			chain = append(chain, s)
+		}
+	}
+	ts, err := l.Translate(ctx, master, chain)
+	if err != nil {
+		return nil, err
+	}
+	translations := make(map[string]map[string]Model)
+	for k, v := range ts {
+		translations[strings.ToLower(k)] = v
+	}
+	for i := range models {
+		key := models[i].Code
+		if len(key) == 0 {
+			key = models[i].Id
+		}
+		// the most specific locale is applied last
+		for j := len(chain) - 1; j >= 0; j-- {
+			if t, ok := translations[strings.ToLower(chain[j])][key]; ok {
+				if len(t.Name) > 0 {
+					models[i].Name = t.Name
+				}
+				if len(t.Text) > 0 {
+					models[i].Text = t.Text
+				}
+			}
+		}
+	}
+	return models, nil
+}
+
+type SqlTranslator struct {
+	DB      *sql.DB
+	Table   string
+	Master  string
+	Code    string
+	Locale  string
+	Name    string
+	Text    string
+	Build   func(i int) string
+	table   string
+	columns string
+	master  string
+	locale  string
+}
+
+func NewSqlTranslator(db *sql.DB, table string, master string, code string, locale string, name string, options ...string) (*SqlTranslator, error) {
+	return NewSqlTranslatorWithDialect(db, DetectDialect(db), table, master, code, locale, name, options...)
+}
+func NewSqlTranslatorWithDialect(db *sql.DB, dialect Dialect, table string, master string, code string, locale string, name string, options ...string) (*SqlTranslator, error) {
+	var text string
+	if len(options) > 0 {
+		text = options[0]
+	}
+	quotedTable, err := QuoteIdentifier(dialect, table)
+	if err != nil {
+		return nil, fmt.Errorf("invalid table: %s", err.Error())
+	}
+	columns := []string{locale, code, name}
+	if len(text) > 0 {
+		columns = append(columns, text)
+	}
+	quoted := make([]string, 0)
+	for _, column := range columns {
+		c, er1 := QuoteIdentifier(dialect, column)
+		if er1 != nil {
+			return nil, er1
+		}
+		quoted = append(quoted, c)
+	}
+	var quotedMaster string
+	if len(master) > 0 {
+		if quotedMaster, err = QuoteIdentifier(dialect, master); err != nil {
+			return nil, err
+		}
+	}
+	return &SqlTranslator{DB: db, Table: table, Master: master, Code: code, Locale: locale, Name: name, Text: text, Build: dialect.Placeholder, table: quotedTable, columns: strings.Join(quoted, ", "), master: quotedMaster, locale: quoted[0]}, nil
+}
+func (t SqlTranslator) Translate(ctx context.Context, master string, locales []string) (map[string]map[string]Model, error) {
+	translations := make(map[string]map[string]Model)
+	if len(locales) == 0 {
+		return translations, nil
+	}
+	values := make([]interface{}, 0)
+	where := make([]string, 0)
+	i := 1
+	if len(t.master) > 0 {
+		where = append(where, fmt.Sprintf("%s = %s", t.master, t.Build(i)))
+		values = append(values, master)
+		i = i + 1
+	}
+	params := make([]string, 0)
+	for _, locale := range locales {
+		params = append(params, t.Build(i))
+		values = append(values, locale)
+		i = i + 1
+	}
+	where = append(where, fmt.Sprintf("%s in (%s)", t.locale, strings.Join(params, ",")))
+	query := fmt.Sprintf("select %s from %s where %s", t.columns, t.table, strings.Join(where, " and "))
+	rows, err := t.DB.QueryContext(ctx, query, values...)
+	if err != nil {
+		return nil, ClassifyError(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var locale, code string
+		var name, text sql.NullString
+		dest := []interface{}{&locale, &code, &name}
+		if len(t.Text) > 0 {
+			dest = append(dest, &text)
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+		if translations[locale] == nil {
+			translations[locale] = make(map[string]Model)
+		}
+		translations[locale][code] = Model{Code: code, Name: name.String, Text: text.String}
+	}
+	return translations, ClassifyError(rows.Err())
+}
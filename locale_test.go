@@ -0,0 +1,178 @@
+package code
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestFallbackLocales(t *testing.T) {
+	tests := []struct {
+		locale        string
+		defaultLocale string
+		want          []string
+	}{
+		{"fr-CA", "en", []string{"fr-CA", "fr", "en"}},
+		{"zh_Hant_TW", "", []string{"zh_Hant_TW", "zh_Hant", "zh"}},
+		{"en-US", "en", []string{"en-US", "en"}},
+		{"", "en", []string{"en"}},
+	}
+	for _, tt := range tests {
+		if got := FallbackLocales(tt.locale, tt.defaultLocale); !equalStrings(got, tt.want) {
+			t.Errorf("FallbackLocales(%q, %q) = %v, want %v", tt.locale, tt.defaultLocale, got, tt.want)
+		}
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	got := ParseAcceptLanguage("fr-CA, en;q=0.5, *;q=0.1, de;q=0, vi;q=0.8")
+	if want := []string{"fr-CA", "vi", "en"}; !equalStrings(got, want) {
+		t.Errorf("ParseAcceptLanguage = %v, want %v", got, want)
+	}
+}
+
+func TestResolveLocale(t *testing.T) {
+	supported := []string{"en", "fr", "vi"}
+	tests := []struct {
+		requested []string
+		want      string
+	}{
+		{[]string{"fr-CA"}, "fr"},
+		{[]string{"FR"}, "fr"},
+		{[]string{"de", "vi-VN"}, "vi"},
+		{[]string{"de"}, "en"},
+		{nil, "en"},
+	}
+	for _, tt := range tests {
+		if got := ResolveLocale(tt.requested, supported, "en"); got != tt.want {
+			t.Errorf("ResolveLocale(%v) = %q, want %q", tt.requested, got, tt.want)
+		}
+	}
+}
+
+func TestLocaleColumn(t *testing.T) {
+	locales := []string{"fr", "fr-CA", "vi"}
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"fr-CA", "coalesce(name_fr_ca, name_fr, name)"},
+		{"fr", "coalesce(name_fr, name)"},
+		{"de", "name"},
+		{"", "name"},
+	}
+	for _, tt := range tests {
+		if got := localeColumn("name", tt.locale, locales); got != tt.want {
+			t.Errorf("localeColumn(%q) = %q, want %q", tt.locale, got, tt.want)
+		}
+	}
+}
+
+func TestLocalizeSelect(t *testing.T) {
+	ctx := WithLocale(context.Background(), "fr")
+	locales := []string{"fr"}
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"column", "select code, name from t where name like ?", "select code, coalesce(name_fr, name) as name from t where name like ?"},
+		{"qualified", "select c.code, c.name, c.sequence from t c", "select c.code, coalesce(c.name_fr, c.name) as name, c.sequence from t c"},
+		{"alias", "select code, label as name from t", "select code, coalesce(label_fr, label) as name from t"},
+		{"alias without as", "select code, c.label name from t c", "select code, coalesce(c.label_fr, c.label) as name from t c"},
+		{"expression", "select code, upper(name) as name from t", "select code, upper(name) as name from t"},
+		{"quoted", `select code, "Name" from t`, `select code, "Name" from t`},
+		{"star", "select * from t", "select * from t"},
+		{"load", "select code, name from t where code in", "select code, coalesce(name_fr, name) as name from t where code in"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := localizeSelect(ctx, tt.query, locales); got != tt.want {
+				t.Errorf("localizeSelect(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+	query := "select code, name from t"
+	if got := localizeSelect(WithLocale(context.Background(), "de"), query, locales); got != query {
+		t.Errorf("localizeSelect of an unsupported locale = %q, want the query", got)
+	}
+	if got := localizeSelect(context.Background(), query, locales); got != query {
+		t.Errorf("localizeSelect without locale = %q, want the query", got)
+	}
+}
+
+func TestLocaleLoader(t *testing.T) {
+	load := func(ctx context.Context, master string) ([]Model, error) {
+		return []Model{{Code: "F", Name: "Female"}, {Code: "M", Name: "Male"}}, nil
+	}
+	var requested []string
+	translate := func(ctx context.Context, master string, locales []string) (map[string]map[string]Model, error) {
+		requested = locales
+		return map[string]map[string]Model{
+			"fr":    {"F": {Name: "Femme"}, "M": {Name: "Homme"}},
+			"fr-CA": {"F": {Name: "Femme CA"}},
+		}, nil
+	}
+	l := NewLocaleLoader(load, translate, "en")
+	models, err := l.Load(WithLocale(context.Background(), "fr-CA"), "gender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if models[0].Name != "Femme CA" || models[1].Name != "Homme" {
+		t.Errorf("Load = %+v, want the names of the most specific locale", models)
+	}
+	if !equalStrings(requested, []string{"fr-CA", "fr"}) {
+		t.Errorf("Translate locales = %v, want the fallback chain without the default locale", requested)
+	}
+	requested = nil
+	models, _ = l.Load(WithLocale(context.Background(), "en"), "gender")
+	if requested != nil || models[0].Name != "Female" {
+		t.Errorf("Load in the default locale = %+v, want the names not translated", models)
+	}
+}
+
+func TestSqlTranslatorIdentifiers(t *testing.T) {
+	if _, err := NewSqlTranslatorWithDialect(nil, PostgresDialect, "code_names; drop table x", "master", "code", "locale", "name"); err == nil {
+		t.Error("NewSqlTranslator of an invalid table, want an error")
+	}
+	if _, err := NewSqlTranslatorWithDialect(nil, PostgresDialect, "code_names", "master", "code", "locale", "name or 1=1"); err == nil {
+		t.Error("NewSqlTranslator of an invalid column, want an error")
+	}
+	l, err := NewSqlTranslatorWithDialect(nil, PostgresDialect, "i18n.code_names", "master", "code", "locale", `"Name"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `locale, code, "Name"`; l.columns != want || l.table != "i18n.code_names" {
+		t.Errorf("columns = %q from %q, want %q", l.columns, l.table, want)
+	}
+}
+
+func TestHandlerLocale(t *testing.T) {
+	load := func(ctx context.Context, master string) ([]Model, error) {
+		return []Model{{Code: "F", Name: GetLocale(ctx)}}, nil
+	}
+	h := NewCodeHandlerByConfig(load, HandlerConfig{Locales: []string{"en", "fr"}, DefaultLocale: "en"}, nil)
+	r := httptest.NewRequest(http.MethodGet, "/codes/gender", nil)
+	r.Header.Set("Accept-Language", "fr-CA,en;q=0.5")
+	w := httptest.NewRecorder()
+	h.Load(w, r)
+	if got := w.Header().Get(LocaleHeader); got != "fr" {
+		t.Errorf("%s = %q, want fr", LocaleHeader, got)
+	}
+	if got := w.Header().Get("Vary"); got != "Accept-Language" {
+		t.Errorf("Vary = %q, want Accept-Language", got)
+	}
+
+	q := NewQueryHandler(func(ctx context.Context, key string, max int64) ([]Model, error) {
+		return []Model{{Code: key, Name: GetLocale(ctx)}}, nil
+	}, nil, nil)
+	q.Locales = []string{"en", "fr"}
+	r = httptest.NewRequest(http.MethodGet, "/codes?q=F", nil)
+	r.Header.Set("Accept-Language", "fr")
+	w = httptest.NewRecorder()
+	q.Query(w, r)
+	if got := w.Header().Get("Vary"); got != "Accept-Language" {
+		t.Errorf("Vary of the query = %q, want Accept-Language", got)
+	}
+}
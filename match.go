@@ -0,0 +1,107 @@
+package code
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const (
+	MatchPrefix    = "prefix"
+	MatchContains  = "contains"
+	MatchWordStart = "word-start"
+	MatchExact     = "exact"
+)
+
+const likeEscape = '!'
+
+type matchKey struct{}
+
+func WithMatch(ctx context.Context, match string) context.Context {
+	return context.WithValue(ctx, matchKey{}, match)
+}
+func GetMatch(ctx context.Context) string {
+	if match, ok := ctx.Value(matchKey{}).(string); ok {
+		return match
+	}
+	return ""
+}
+func isMatch(match string) bool {
+	return match == MatchPrefix || match == MatchContains || match == MatchWordStart || match == MatchExact
+}
+
+// GetRequestMatch returns the match mode of the request parameter, or an error if it is not supported.
+func GetRequestMatch(r *http.Request, param string) (string, error) {
+	if len(param) == 0 {
+		return "", nil
+	}
+	match := r.URL.Query().Get(param)
+	if len(match) > 0 && !isMatch(match) {
+		return match, invalidInput("match mode '%s' is not supported", match)
+	}
+	return match, nil
+}
+
+// ResolveMatch returns the match mode of the request if any, else the match mode of the query, else MatchPrefix.
+func ResolveMatch(ctx context.Context, match string) (string, error) {
+	if m := GetMatch(ctx); len(m) > 0 {
+		match = m
+	}
+	if len(match) == 0 {
+		return MatchPrefix, nil
+	}
+	if !isMatch(match) {
+		return match, invalidInput("match mode '%s' is not supported", match)
+	}
+	return match, nil
+}
+
+// EscapeLike escapes the wildcards of the key with the escape character of Dialect.Like.
+func EscapeLike(key string, dialect Dialect) string {
+	var b strings.Builder
+	for _, c := range key {
+		if c == likeEscape || c == '%' || c == '_' || (c == '[' && dialect.Name() == driverMssql) {
+			b.WriteRune(likeEscape)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
+
+// buildPattern returns the pattern of the key. The wildcards of the key are escaped if the condition has an escape clause, else they are removed, as '%' cannot be escaped.
+func buildPattern(key string, match string, dialect Dialect, escape bool) string {
+	if escape {
+		key = EscapeLike(key, dialect)
+	} else {
+		key = strings.NewReplacer("%", "", "?", "").Replace(key)
+	}
+	switch match {
+	case MatchContains:
+		return "%" + key + "%"
+	case MatchWordStart:
+		// the column is prefixed with a space, so that the first word matches too
+		return "% " + key + "%"
+	case MatchExact:
+		return key
+	default:
+		return key + "%"
+	}
+}
+
+// Match reports whether s matches the key by the match mode.
+func Match(s string, key string, match string, ignoreCase bool) bool {
+	if ignoreCase {
+		s = strings.ToLower(s)
+		key = strings.ToLower(key)
+	}
+	switch match {
+	case MatchContains:
+		return strings.Contains(s, key)
+	case MatchWordStart:
+		return strings.HasPrefix(s, key) || strings.Contains(s, " "+key)
+	case MatchExact:
+		return s == key
+	default:
+		return strings.HasPrefix(s, key)
+	}
+}
@@ -0,0 +1,136 @@
+package code
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestEscapeLike(t *testing.T) {
+	tests := []struct {
+		key     string
+		dialect Dialect
+		want    string
+	}{
+		{"50%", PostgresDialect, "50!%"},
+		{"a_b", MySqlDialect, "a!_b"},
+		{"wow!", SqliteDialect, "wow!!"},
+		{"[a]", MsSqlDialect, "![a]"},
+		{"[a]", PostgresDialect, "[a]"},
+	}
+	for _, tt := range tests {
+		if got := EscapeLike(tt.key, tt.dialect); got != tt.want {
+			t.Errorf("EscapeLike(%q, %s) = %q, want %q", tt.key, tt.dialect.Name(), got, tt.want)
+		}
+	}
+}
+
+func TestBuildPattern(t *testing.T) {
+	tests := []struct {
+		key    string
+		match  string
+		escape bool
+		want   string
+	}{
+		{"ab", MatchPrefix, true, "ab%"},
+		{"ab", MatchContains, true, "%ab%"},
+		{"ab", MatchWordStart, true, "% ab%"},
+		{"ab", MatchExact, true, "ab"},
+		{"5%_", MatchPrefix, true, "5!%!_%"},
+		{"5%?", MatchPrefix, false, "5%"},
+		{"5%", MatchExact, false, "5"},
+	}
+	for _, tt := range tests {
+		if got := buildPattern(tt.key, tt.match, PostgresDialect, tt.escape); got != tt.want {
+			t.Errorf("buildPattern(%q, %s, %v) = %q, want %q", tt.key, tt.match, tt.escape, got, tt.want)
+		}
+	}
+}
+
+func TestRewriteLike(t *testing.T) {
+	tests := []struct {
+		name       string
+		dialect    Dialect
+		match      string
+		ignoreCase bool
+		query      string
+		want       string
+		escaped    []int
+	}{
+		{"postgres", PostgresDialect, MatchPrefix, false, "select code, name from t where name like ?", "select code, name from t where name like ? escape '!'", []int{1}},
+		{"postgres ignore case", PostgresDialect, MatchPrefix, true, "select code, name from t where name like ?", "select code, name from t where name ilike ? escape '!'", []int{1}},
+		{"mysql ignore case", MySqlDialect, MatchPrefix, true, "select code, name from t where name like ?", "select code, name from t where lower(name) like lower(?) escape '!'", []int{1}},
+		{"oracle ignore case", OracleDialect, MatchContains, true, "select code, name from t where name like :name", "select code, name from t where lower(name) like lower(:name) escape '!'", []int{1}},
+		{"word start", PostgresDialect, MatchWordStart, false, "select code, name from t where name like ?", "select code, name from t where (' ' || name) like ? escape '!'", []int{1}},
+		{"mssql word start", MsSqlDialect, MatchWordStart, false, "select code, name from t where name like @name", "select code, name from t where concat(' ', name) like @name escape '!'", []int{1}},
+		{"function operand", SqliteDialect, MatchPrefix, false, "select code, name from t where lower(name) like ?", "select code, name from t where lower(name) like ? escape '!'", []int{1}},
+		{"quoted operand", PostgresDialect, MatchPrefix, false, `select code, name from t where "Name" like ?`, `select code, name from t where "Name" like ? escape '!'`, []int{1}},
+		{"bracket operand", MsSqlDialect, MatchPrefix, false, "select code, name from t where [name] like @name", "select code, name from t where [name] like @name escape '!'", []int{1}},
+		{"not like", PostgresDialect, MatchPrefix, false, "select code, name from t where name not like ?", "select code, name from t where name not like ?", nil},
+		{"escape clause", PostgresDialect, MatchPrefix, false, `select code, name from t where name like ? escape '\'`, `select code, name from t where name like ? escape '\'`, nil},
+		{"ilike", PostgresDialect, MatchPrefix, false, "select code, name from t where name ilike ?", "select code, name from t where name ilike ?", nil},
+		{"pattern expression", PostgresDialect, MatchPrefix, false, "select code, name from t where name like ? || '%'", "select code, name from t where name like ? || '%'", nil},
+		{"cast pattern", PostgresDialect, MatchPrefix, false, "select code, name from t where name like ?::text", "select code, name from t where name like ?::text", nil},
+		{"function pattern", MySqlDialect, MatchPrefix, false, "select code, name from t where code like ? or name like concat(?, '%')", "select code, name from t where code like ? escape '!' or name like concat(?, '%')", []int{1}},
+		{"second parameter", MySqlDialect, MatchPrefix, false, "select code, name from t where code = ? and name like ?", "select code, name from t where code = ? and name like ? escape '!'", []int{2}},
+		{"literal", SqliteDialect, MatchPrefix, false, "select code, name from t where kind <> 'x like ?' and name like ?", "select code, name from t where kind <> 'x like ?' and name like ? escape '!'", []int{1}},
+		{"named", MsSqlDialect, MatchPrefix, false, "select code, name from t where code = @code and name like @name", "select code, name from t where code = @code and name like @name escape '!'", []int{2}},
+		{"positional", PostgresDialect, MatchPrefix, false, "select code, name from t where name like $2 and code = $1", "select code, name from t where name like $2 escape '!' and code = $1", []int{2}},
+		{"parenthesis", PostgresDialect, MatchPrefix, false, "select code, name from t where (code like ?) or (name like ?)", "select code, name from t where (code like ? escape '!') or (name like ? escape '!')", []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, escaped := rewriteLike(tt.query, tt.dialect, tt.match, tt.ignoreCase)
+			want := make(map[int]bool)
+			for _, i := range tt.escaped {
+				want[i] = true
+			}
+			if got != tt.want || !reflect.DeepEqual(escaped, want) {
+				t.Errorf("rewriteLike(%q) = %q, %v, want %q, %v", tt.query, got, escaped, tt.want, want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		s          string
+		key        string
+		match      string
+		ignoreCase bool
+		want       bool
+	}{
+		{"Ho Chi Minh", "Ho", MatchPrefix, false, true},
+		{"Ho Chi Minh", "chi", MatchPrefix, true, false},
+		{"Ho Chi Minh", "chi", MatchContains, true, true},
+		{"Ho Chi Minh", "Chi", MatchWordStart, false, true},
+		{"Ho Chi Minh", "hi", MatchWordStart, false, false},
+		{"Ho Chi Minh", "ho chi minh", MatchExact, true, true},
+		{"Ho Chi Minh", "Ho Chi", MatchExact, false, false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.s, tt.key, tt.match, tt.ignoreCase); got != tt.want {
+			t.Errorf("Match(%q, %q, %s, %v) = %v, want %v", tt.s, tt.key, tt.match, tt.ignoreCase, got, tt.want)
+		}
+	}
+}
+
+func TestResolveMatch(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/codes?match=fuzzy", nil)
+	if _, err := GetRequestMatch(r, "match"); err == nil {
+		t.Error("GetRequestMatch of an unknown mode, want an error")
+	}
+	r = httptest.NewRequest(http.MethodGet, "/codes?match=contains", nil)
+	match, err := GetRequestMatch(r, "match")
+	if err != nil || match != MatchContains {
+		t.Errorf("GetRequestMatch = %q, %v, want %s", match, err, MatchContains)
+	}
+	if match, _ = ResolveMatch(WithMatch(context.Background(), MatchExact), MatchContains); match != MatchExact {
+		t.Errorf("ResolveMatch = %q, want the match mode of the request", match)
+	}
+	if match, _ = ResolveMatch(context.Background(), ""); match != MatchPrefix {
+		t.Errorf("ResolveMatch = %q, want %s by default", match, MatchPrefix)
+	}
+}
@@ -0,0 +1,156 @@
+package mongo
+
+import (
+	"context"
+	"regexp"
+
+	co "github.com/core-go/code"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type Loader struct {
+	Collection *mongo.Collection
+	Config     co.StructureConfig
+}
+
+func NewCodeLoader(db *mongo.Database, collection string, config co.StructureConfig) *Loader {
+	return NewCodeLoaderWithCollection(db.Collection(collection), config)
+}
+func NewCodeLoaderWithCollection(collection *mongo.Collection, config co.StructureConfig) *Loader {
+	return &Loader{Collection: collection, Config: config}
+}
+func (l Loader) Load(ctx context.Context, master string) ([]co.Model, error) {
+	filter := bson.M{}
+	if len(l.Config.Master) > 0 {
+		filter[l.Config.Master] = master
+	}
+	return find(ctx, l.Collection, l.Config, filter, 0)
+}
+
+type Query struct {
+	Collection *mongo.Collection
+	Config     co.StructureConfig
+	Fields     []string
+	Key        string
+	Match      string
+	IgnoreCase bool
+}
+
+func NewQuery(db *mongo.Database, collection string, config co.StructureConfig, fields ...string) *Query {
+	return NewQueryWithCollection(db.Collection(collection), config, fields...)
+}
+func NewQueryWithCollection(collection *mongo.Collection, config co.StructureConfig, fields ...string) *Query {
+	if len(fields) == 0 {
+		if len(config.Name) > 0 {
+			fields = append(fields, config.Name)
+		} else {
+			fields = append(fields, "name")
+		}
+	}
+	key := config.Id
+	if len(key) == 0 {
+		key = "_id"
+	}
+	return &Query{Collection: collection, Config: config, Fields: fields, Key: key, Match: co.MatchPrefix}
+}
+func (q Query) Query(ctx context.Context, key string, max int64) ([]co.Model, error) {
+	if max <= 0 {
+		max = 20
+	}
+	match, err := co.ResolveMatch(ctx, q.Match)
+	if err != nil {
+		return nil, err
+	}
+	pattern := regexp.QuoteMeta(key)
+	switch match {
+	case co.MatchContains:
+	case co.MatchWordStart:
+		pattern = `(^|\s)` + pattern
+	case co.MatchExact:
+		pattern = "^" + pattern + "$"
+	default:
+		pattern = "^" + pattern
+	}
+	options := ""
+	if q.IgnoreCase {
+		options = "i"
+	}
+	or := make([]bson.M, 0)
+	for _, field := range q.Fields {
+		or = append(or, bson.M{field: bson.M{"$regex": pattern, "$options": options}})
+	}
+	filter := bson.M{"$or": or}
+	return find(ctx, q.Collection, q.Config, filter, max)
+}
+func (q Query) Load(ctx context.Context, keys []string) ([]co.Model, error) {
+	if len(keys) == 0 {
+		return make([]co.Model, 0), nil
+	}
+	filter := bson.M{q.Key: bson.M{"$in": keys}}
+	return find(ctx, q.Collection, q.Config, filter, 0)
+}
+
+func find(ctx context.Context, collection *mongo.Collection, c co.StructureConfig, filter bson.M, max int64) ([]co.Model, error) {
+	if len(c.Status) > 0 && c.Active != nil {
+		filter[c.Status] = c.Active
+	}
+	pipeline := mongo.Pipeline{bson.D{{Key: "$match", Value: filter}}}
+	if len(c.Sequence) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: c.Sequence, Value: 1}}}})
+	}
+	if max > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$limit", Value: max}})
+	}
+	if project := buildProjection(c); len(project) > 0 {
+		pipeline = append(pipeline, bson.D{{Key: "$project", Value: project}})
+	}
+	cursor, er1 := collection.Aggregate(ctx, pipeline)
+	if er1 != nil {
+		return nil, classifyError(er1)
+	}
+	models := make([]co.Model, 0)
+	if er2 := cursor.All(ctx, &models); er2 != nil {
+		return nil, classifyError(er2)
+	}
+	return models, nil
+}
+func classifyError(err error) error {
+	if mongo.IsTimeout(err) {
+		return &co.KindError{Kind: co.ErrTimeout, Err: err}
+	}
+	if mongo.IsNetworkError(err) {
+		return &co.KindError{Kind: co.ErrUnavailable, Err: err}
+	}
+	return co.ClassifyError(err)
+}
+
+// buildProjection renames the configured fields to the bson names of co.Model, the same way SqlLoader aliases columns.
+func buildProjection(c co.StructureConfig) bson.M {
+	project := bson.M{}
+	if len(c.Id) > 0 {
+		project["id"] = bson.M{"$toString": "$" + c.Id}
+	}
+	if len(c.Code) > 0 {
+		project["code"] = "$" + c.Code
+	}
+	if len(c.Name) > 0 {
+		project["name"] = "$" + c.Name
+	}
+	if len(c.Value) > 0 {
+		project["value"] = "$" + c.Value
+	}
+	if len(c.Text) > 0 {
+		project["text"] = "$" + c.Text
+	}
+	if len(c.Parent) > 0 {
+		project["parent"] = "$" + c.Parent
+	}
+	if len(c.Sequence) > 0 {
+		project["sequence"] = "$" + c.Sequence
+	}
+	if len(project) > 0 {
+		project["_id"] = 0
+	}
+	return project
+}
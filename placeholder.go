@@ -0,0 +1,442 @@
+package code
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RewriteQuery rewrites the placeholders of the query, '?', ':name' and '@name', to the placeholders of the dialect, and returns the number of parameters.
+// String literals, quoted identifiers, comments, dollar quoted strings and the JSON operators '?|' and '?&' are kept as is; '??' is a literal '?'.
+// Each occurrence of a named placeholder is a parameter, as the drivers which bind by position require.
+func RewriteQuery(query string, dialect Dialect) (string, int) {
+	var b strings.Builder
+	count := 0
+	maxNumber := 0
+	n := len(query)
+	for i := 0; i < n; {
+		if j := skipLiteral(query, i); j > i {
+			b.WriteString(query[i:j])
+			i = j
+			continue
+		}
+		c := query[i]
+		var next byte
+		if i+1 < n {
+			next = query[i+1]
+		}
+		j := i + 1
+		switch {
+		case c == '$' && isDigit(next):
+			j = i + 1
+			for j < n && isDigit(query[j]) {
+				j++
+			}
+			if number, err := strconv.Atoi(query[i+1 : j]); err == nil && number > maxNumber {
+				maxNumber = number
+			}
+		case c == '?':
+			if next == '|' || next == '&' {
+				j = i + 2
+			} else if next == '?' {
+				b.WriteByte('?')
+				i = i + 2
+				continue
+			} else {
+				count++
+				b.WriteString(dialect.Placeholder(count))
+				i = i + 1
+				continue
+			}
+		case (c == ':' || c == '@') && isIdentStart(next) && (i == 0 || query[i-1] != c):
+			j = i + 1
+			for j < n && isIdentChar(query[j]) {
+				j++
+			}
+			count++
+			b.WriteString(dialect.Placeholder(count))
+			i = j
+			continue
+		}
+		b.WriteString(query[i:j])
+		i = j
+	}
+	if maxNumber > count {
+		count = maxNumber
+	}
+	return b.String(), count
+}
+
+// rewriteLike rewrites the conditions 'operand like placeholder' of the query with Dialect.Like, for the match mode, and returns the indexes of the rewritten parameters, which have an escape clause.
+// The conditions with 'not like' or with an escape clause are kept as is.
+func rewriteLike(query string, dialect Dialect, match string, ignoreCase bool) (string, map[int]bool) {
+	var b strings.Builder
+	escaped := make(map[int]bool)
+	n := len(query)
+	for i := 0; i < n; {
+		if j := skipLiteral(query, i); j > i {
+			b.WriteString(query[i:j])
+			i = j
+			continue
+		}
+		c := query[i]
+		if !isIdentStart(c) || (i > 0 && (isIdentChar(query[i-1]) || query[i-1] == '$' || query[i-1] == ':' || query[i-1] == '@')) {
+			b.WriteByte(c)
+			i++
+			continue
+		}
+		j := i + 1
+		for j < n && isIdentChar(query[j]) {
+			j++
+		}
+		if strings.EqualFold(query[i:j], "like") {
+			if start, end := likeParam(query, j); end > 0 {
+				out := b.String()
+				if k := operandStart(out); k >= 0 {
+					operand := strings.TrimSpace(out[k:])
+					if match == MatchWordStart {
+						operand = dialect.Concat("' '", operand)
+					}
+					b.Reset()
+					b.WriteString(out[:k])
+					b.WriteString(dialect.Like(operand, query[start:end], ignoreCase))
+					escaped[paramIndex(query, start, end, dialect)] = true
+					i = end
+					continue
+				}
+			}
+		}
+		b.WriteString(query[i:j])
+		i = j
+	}
+	return b.String(), escaped
+}
+
+// paramIndex returns the index of the parameter of the placeholder, from 1.
+func paramIndex(query string, start int, end int, dialect Dialect) int {
+	if query[start] == '$' {
+		if index, err := strconv.Atoi(query[start+1 : end]); err == nil {
+			return index
+		}
+	}
+	_, count := RewriteQuery(query[:start], dialect)
+	return count + 1
+}
+
+// likeParam returns the bounds of the placeholder after like, or 0, 0 if the pattern is not a placeholder alone or has an escape clause.
+func likeParam(query string, i int) (int, int) {
+	n := len(query)
+	for i < n && isSpace(query[i]) {
+		i++
+	}
+	if i >= n {
+		return 0, 0
+	}
+	start := i
+	c := query[i]
+	var next byte
+	if i+1 < n {
+		next = query[i+1]
+	}
+	switch {
+	case c == '?' && next != '?' && next != '|' && next != '&':
+		i = i + 1
+	case (c == ':' || c == '@') && isIdentStart(next):
+		i = i + 2
+		for i < n && isIdentChar(query[i]) {
+			i++
+		}
+	case c == '$' && isDigit(next):
+		i = i + 2
+		for i < n && isDigit(query[i]) {
+			i++
+		}
+	default:
+		return 0, 0
+	}
+	end := i
+	for i < n && isSpace(query[i]) {
+		i++
+	}
+	// the pattern is the placeholder only, not an expression such as '? || ...' or '?::text'
+	if i < n && query[i] != ')' && query[i] != ',' && query[i] != ';' && !isIdentStart(query[i]) {
+		return 0, 0
+	}
+	k := i
+	for k < n && isIdentChar(query[k]) {
+		k++
+	}
+	if strings.EqualFold(query[i:k], "escape") {
+		return 0, 0
+	}
+	return start, end
+}
+
+// operandStart returns the start of the operand at the end of s, a column, a quoted identifier or a function call, or -1 if there is none, such as for 'not like'.
+func operandStart(s string) int {
+	e := len(strings.TrimRight(s, " \t\r\n"))
+	i := e
+	if i > 0 && s[i-1] == ')' {
+		depth := 0
+		for i = i - 1; i >= 0; i-- {
+			if s[i] == ')' {
+				depth++
+			} else if s[i] == '(' {
+				depth--
+				if depth == 0 {
+					break
+				}
+			}
+		}
+		if i < 0 {
+			return -1
+		}
+	}
+	for i > 0 {
+		c := s[i-1]
+		k := i - 1
+		if c == '"' || c == '`' {
+			k = strings.LastIndexByte(s[:i-1], c)
+		} else if c == ']' {
+			k = strings.LastIndexByte(s[:i-1], '[')
+		} else if !isIdentChar(c) && c != '.' && c != '$' && c != '#' {
+			break
+		}
+		if k < 0 {
+			return -1
+		}
+		i = k
+	}
+	if i == e || strings.EqualFold(s[i:e], "not") {
+		return -1
+	}
+	return i
+}
+
+// skipLiteral returns the end of the string literal, quoted identifier, comment or dollar quoted string at i, or i if there is none.
+func skipLiteral(query string, i int) int {
+	n := len(query)
+	c := query[i]
+	var next byte
+	if i+1 < n {
+		next = query[i+1]
+	}
+	switch {
+	case c == '\'':
+		escape := i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i < 2 || !isIdentChar(query[i-2]))
+		return skipQuoted(query, i, '\'', escape)
+	case c == '"' || c == '`':
+		return skipQuoted(query, i, c, false)
+	case c == '-' && next == '-':
+		if k := strings.IndexByte(query[i:], '\n'); k >= 0 {
+			return i + k + 1
+		}
+		return n
+	case c == '/' && next == '*':
+		if k := strings.Index(query[i+2:], "*/"); k >= 0 {
+			return i + 2 + k + 2
+		}
+		return n
+	case c == '$' && (next == '$' || isIdentStart(next)) && (i == 0 || !isIdentChar(query[i-1])):
+		return skipDollarQuoted(query, i)
+	}
+	return i
+}
+func skipQuoted(query string, i int, quote byte, escape bool) int {
+	n := len(query)
+	for j := i + 1; j < n; j++ {
+		if escape && query[j] == '\\' {
+			j++
+			continue
+		}
+		if query[j] == quote {
+			if j+1 < n && query[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return n
+}
+func skipDollarQuoted(query string, i int) int {
+	k := strings.IndexByte(query[i+1:], '$')
+	if k < 0 {
+		return i + 1
+	}
+	tag := query[i : i+1+k+1]
+	for _, ch := range []byte(tag[1 : len(tag)-1]) {
+		if !isIdentChar(ch) {
+			return i + 1
+		}
+	}
+	end := strings.Index(query[i+len(tag):], tag)
+	if end < 0 {
+		return len(query)
+	}
+	return i + len(tag) + end + len(tag)
+}
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+type token struct {
+	text  string
+	start int
+	depth int
+}
+
+// tokenize splits the query into words, numbers, quoted identifiers, string literals and symbols, without the comments. The depth is the depth of the parentheses of the token.
+func tokenize(query string) []token {
+	tokens := make([]token, 0)
+	depth := 0
+	n := len(query)
+	for i := 0; i < n; {
+		c := query[i]
+		if j := skipLiteral(query, i); j > i {
+			if c != '-' && c != '/' {
+				tokens = append(tokens, token{text: query[i:j], start: i, depth: depth})
+			}
+			i = j
+			continue
+		}
+		j := i + 1
+		switch {
+		case isSpace(c):
+			i = j
+			continue
+		case isIdentStart(c) || isDigit(c):
+			for j < n && (isIdentChar(query[j]) || query[j] == '$' || query[j] == '#') {
+				j++
+			}
+		case c == '[':
+			if k := strings.IndexByte(query[i:], ']'); k > 0 {
+				j = i + k + 1
+			}
+		case c == '(':
+			tokens = append(tokens, token{text: "(", start: i, depth: depth})
+			depth++
+			i = j
+			continue
+		case c == ')':
+			if depth > 0 {
+				depth--
+			}
+		}
+		tokens = append(tokens, token{text: query[i:j], start: i, depth: depth})
+		i = j
+	}
+	return tokens
+}
+
+// hasKeywords reports whether the query has the keywords in sequence, such as 'order by', out of the parentheses.
+func hasKeywords(tokens []token, keywords ...string) bool {
+	for i := 0; i+len(keywords) <= len(tokens); i++ {
+		found := true
+		for k, keyword := range keywords {
+			if t := tokens[i+k]; t.depth != 0 || !strings.EqualFold(t.text, keyword) {
+				found = false
+				break
+			}
+		}
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+type selectColumn struct {
+	name   string
+	quoted bool
+}
+
+// selectColumns returns the names of the columns of the result set of the query, from the select list, or false if they are not known, such as for '*'.
+// A column without alias, which is an expression, has no name.
+func selectColumns(query string) ([]selectColumn, bool) {
+	items, ok := selectItems(tokenize(query))
+	if !ok {
+		return nil, false
+	}
+	columns := make([]selectColumn, 0)
+	for _, item := range items {
+		columns = append(columns, itemColumn(item))
+	}
+	return columns, true
+}
+
+// selectItems returns the tokens of the items of the select list, or false if the columns are not known, such as for '*'.
+func selectItems(tokens []token) ([][]token, bool) {
+	i := 0
+	for i < len(tokens) && !(tokens[i].depth == 0 && strings.EqualFold(tokens[i].text, "select")) {
+		i++
+	}
+	if i == len(tokens) {
+		return nil, false
+	}
+	i++
+	for i < len(tokens) && (strings.EqualFold(tokens[i].text, "distinct") || strings.EqualFold(tokens[i].text, "all")) {
+		i++
+	}
+	if i < len(tokens) && strings.EqualFold(tokens[i].text, "top") {
+		i = i + 2
+		for i < len(tokens) && tokens[i].depth > 0 {
+			i++
+		}
+		if i < len(tokens) && tokens[i].text == ")" {
+			i++
+		}
+	}
+	items := make([][]token, 0)
+	item := make([]token, 0)
+	for ; i <= len(tokens); i++ {
+		end := i == len(tokens) || (tokens[i].depth == 0 && strings.EqualFold(tokens[i].text, "from"))
+		if !end && (tokens[i].depth > 0 || tokens[i].text != ",") {
+			item = append(item, tokens[i])
+			continue
+		}
+		if len(item) == 0 || item[len(item)-1].text == "*" {
+			return nil, false
+		}
+		items = append(items, item)
+		item = make([]token, 0)
+		if end {
+			break
+		}
+	}
+	return items, true
+}
+
+// itemColumn returns the name of an item of the select list: the alias, or the column, such as 'c.code'.
+func itemColumn(item []token) selectColumn {
+	last := item[len(item)-1]
+	name, quoted := last.text, false
+	if inner, ok := unquote(name); ok {
+		name, quoted = inner, true
+	} else if !isIdentStart(name[0]) {
+		return selectColumn{}
+	}
+	column := len(item)%2 == 1
+	for k, t := range item {
+		if (k%2 == 1) != (t.text == ".") {
+			column = false
+		}
+	}
+	if column {
+		return selectColumn{name: name, quoted: quoted}
+	}
+	// an alias follows 'as', a column, a literal or a function call, but not an operator
+	prev := item[len(item)-2].text
+	if prev != "." && (prev == ")" || isIdentChar(prev[0]) || prev[0] == '\'' || prev[0] == '"' || prev[0] == '`' || prev[0] == '[') {
+		return selectColumn{name: name, quoted: quoted}
+	}
+	return selectColumn{}
+}
@@ -0,0 +1,39 @@
+package code
+
+import "testing"
+
+func TestRewriteQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+		count   int
+	}{
+		{"postgres", PostgresDialect, "select * from t where a = ? and b = ?", "select * from t where a = $1 and b = $2", 2},
+		{"oracle", OracleDialect, "select * from t where a = ? and b = ?", "select * from t where a = :val1 and b = :val2", 2},
+		{"mssql", MsSqlDialect, "select * from t where a = ? and b = ?", "select * from t where a = @p1 and b = @p2", 2},
+		{"mysql", MySqlDialect, "select * from t where a = ? and b = ?", "select * from t where a = ? and b = ?", 2},
+		{"sqlite", SqliteDialect, "select * from t where a = :a", "select * from t where a = ?", 1},
+		{"string literal", PostgresDialect, "select '?' from t where a = ?", "select '?' from t where a = $1", 1},
+		{"doubled quote", PostgresDialect, "select 'it''s ?', a from t where b = ?", "select 'it''s ?', a from t where b = $1", 1},
+		{"escape string", PostgresDialect, `select E'\'?' from t where a = ?`, `select E'\'?' from t where a = $1`, 1},
+		{"quoted identifiers", PostgresDialect, "select \"a?b\", `c?` from t where d = ?", "select \"a?b\", `c?` from t where d = $1", 1},
+		{"comments", PostgresDialect, "select a -- ?\nfrom t /* ? */ where b = ?", "select a -- ?\nfrom t /* ? */ where b = $1", 1},
+		{"cast", PostgresDialect, "select a::text from t where b = :b", "select a::text from t where b = $1", 1},
+		{"system variable", MsSqlDialect, "select @@version, a from t where b = @b", "select @@version, a from t where b = @p1", 1},
+		{"json operators", PostgresDialect, "select a from t where tags ?| array['x'] and keys ?& array['y'] and b = ?", "select a from t where tags ?| array['x'] and keys ?& array['y'] and b = $1", 1},
+		{"escaped question mark", PostgresDialect, "select a from t where j ?? 'k' and b = ?", "select a from t where j ? 'k' and b = $1", 1},
+		{"dollar quotes", PostgresDialect, "select $$?$$, $tag$ ? $tag$ from t where a = ?", "select $$?$$, $tag$ ? $tag$ from t where a = $1", 1},
+		{"positional", PostgresDialect, "select a from t where b = $1 or c = $2", "select a from t where b = $1 or c = $2", 2},
+		{"repeated name", OracleDialect, "select a from t where b = :key or c = :key", "select a from t where b = :val1 or c = :val2", 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, count := RewriteQuery(tt.query, tt.dialect)
+			if got != tt.want || count != tt.count {
+				t.Errorf("RewriteQuery(%q) = %q, %d, want %q, %d", tt.query, got, count, tt.want, tt.count)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	co "github.com/core-go/code"
+	"github.com/lib/pq"
+)
+
+const pingInterval = 90 * time.Second
+
+type Listener struct {
+	DataSource   string
+	Channel      string
+	Invalidate   func(master string)
+	Clear        func()
+	Error        func(context.Context, string, ...map[string]interface{})
+	MinReconnect time.Duration
+	MaxReconnect time.Duration
+	mu           sync.Mutex
+	listener     *pq.Listener
+	stop         chan struct{}
+	done         chan struct{}
+}
+
+func NewListener(dataSource string, channel string, invalidate func(master string), options ...func()) *Listener {
+	var clear func()
+	if len(options) > 0 {
+		clear = options[0]
+	}
+	return &Listener{DataSource: dataSource, Channel: channel, Invalidate: invalidate, Clear: clear, MinReconnect: time.Second, MaxReconnect: time.Minute}
+}
+func (l *Listener) Start(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.listener != nil {
+		return errors.New("listener is already started")
+	}
+	listener := pq.NewListener(l.DataSource, l.MinReconnect, l.MaxReconnect, func(event pq.ListenerEventType, err error) {
+		if err != nil && l.Error != nil {
+			l.Error(ctx, fmt.Sprintf("listener of channel '%s': %s", l.Channel, err.Error()))
+		}
+	})
+	if err := listener.Listen(l.Channel); err != nil {
+		listener.Close()
+		return err
+	}
+	l.listener = listener
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	go l.run(ctx, listener, l.stop, l.done)
+	return nil
+}
+func (l *Listener) Stop() error {
+	l.mu.Lock()
+	listener, stop, done := l.listener, l.stop, l.done
+	l.listener, l.stop, l.done = nil, nil, nil
+	l.mu.Unlock()
+	if listener == nil {
+		return nil
+	}
+	close(stop)
+	<-done
+	return listener.Close()
+}
+func (l *Listener) run(ctx context.Context, listener *pq.Listener, stop chan struct{}, done chan struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case n := <-listener.NotificationChannel():
+			if n == nil {
+				// the connection was re-established, notifications sent while disconnected are lost
+				if l.Clear != nil {
+					l.Clear()
+				}
+			} else if len(n.Extra) == 0 {
+				// the trigger of a table without master notifies an empty payload, so all the codes are cleared
+				if l.Clear != nil {
+					l.Clear()
+				}
+			} else if l.Invalidate != nil {
+				l.Invalidate(n.Extra)
+			}
+		case <-ticker.C:
+			go listener.Ping()
+		case <-stop:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func InstallTrigger(ctx context.Context, db *sql.DB, table string, master string, channel string) error {
+	name := strings.Replace(table, ".", "_", -1) + "_notify"
+	quotedTable, err := co.QuoteIdentifier(co.PostgresDialect, table)
+	if err != nil {
+		return err
+	}
+	var payload, oldPayload string
+	if len(master) > 0 {
+		quotedMaster, er1 := co.QuoteIdentifier(co.PostgresDialect, master)
+		if er1 != nil {
+			return er1
+		}
+		payload = fmt.Sprintf("new.%s::text", quotedMaster)
+		oldPayload = fmt.Sprintf("old.%s::text", quotedMaster)
+	} else {
+		payload = "''"
+		oldPayload = "''"
+	}
+	function := fmt.Sprintf(`create or replace function %s() returns trigger as $$
+begin
+  if tg_op = 'DELETE' then
+    perform pg_notify(%s, %s);
+  else
+    perform pg_notify(%s, %s);
+    if tg_op = 'UPDATE' and %s is distinct from %s then
+      perform pg_notify(%s, %s);
+    end if;
+  end if;
+  return null;
+end;
+$$ language plpgsql`, pq.QuoteIdentifier(name), pq.QuoteLiteral(channel), oldPayload, pq.QuoteLiteral(channel), payload, oldPayload, payload, pq.QuoteLiteral(channel), oldPayload)
+	drop := fmt.Sprintf("drop trigger if exists %s on %s", pq.QuoteIdentifier(name), quotedTable)
+	create := fmt.Sprintf("create trigger %s after insert or update or delete on %s for each row execute procedure %s()", pq.QuoteIdentifier(name), quotedTable, pq.QuoteIdentifier(name))
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	for _, stmt := range []string{function, drop, create} {
+		if _, err = tx.ExecContext(ctx, stmt); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,173 @@
+package code
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const (
+	scoreExactCode = 4
+	scoreExactName = 3
+	scorePrefix    = 2
+	scoreContains  = 1
+)
+
+// Score returns the relevance of a code for the key: the exact code, then the exact name, then a prefix, then a part of the code or the name.
+func Score(code string, name string, key string, ignoreCase bool) int32 {
+	if ignoreCase {
+		code = strings.ToLower(code)
+		name = strings.ToLower(name)
+		key = strings.ToLower(key)
+	}
+	switch {
+	case code == key:
+		return scoreExactCode
+	case name == key:
+		return scoreExactName
+	case strings.HasPrefix(code, key) || strings.HasPrefix(name, key):
+		return scorePrefix
+	case strings.Contains(code, key) || strings.Contains(name, key):
+		return scoreContains
+	}
+	return 0
+}
+
+type rankFields struct {
+	code     int
+	name     int
+	sequence int
+	score    int
+}
+
+func getRankFields(modelType reflect.Type) rankFields {
+	f := rankFields{code: -1, name: -1, sequence: -1, score: -1}
+	if modelType.Kind() != reflect.Struct {
+		return f
+	}
+	for name, index := range map[string]*int{"Code": &f.code, "Name": &f.name, "Sequence": &f.sequence, "Score": &f.score} {
+		if field, ok := modelType.FieldByName(name); ok && len(field.Index) == 1 {
+			*index = field.Index[0]
+		}
+	}
+	return f
+}
+func (f rankFields) getScore(v reflect.Value, key string, ignoreCase bool) int32 {
+	var code, name string
+	if f.code >= 0 {
+		code = fmt.Sprint(v.Field(f.code).Interface())
+	}
+	if f.name >= 0 {
+		name = fmt.Sprint(v.Field(f.name).Interface())
+	}
+	return Score(code, name, key, ignoreCase)
+}
+func (f rankFields) getSequence(v reflect.Value) int64 {
+	if f.sequence < 0 {
+		return 0
+	}
+	switch s := v.Field(f.sequence); s.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return s.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(s.Uint())
+	}
+	return 0
+}
+
+// RankModels sorts the models by score, then by sequence.
+func RankModels[T any](models []T, key string, ignoreCase bool) {
+	f := getRankFields(reflect.TypeOf((*T)(nil)).Elem())
+	scores := make([]int32, len(models))
+	sequences := make([]int64, len(models))
+	for i := range models {
+		v := reflect.ValueOf(&models[i]).Elem()
+		scores[i] = f.getScore(v, key, ignoreCase)
+		sequences[i] = f.getSequence(v)
+	}
+	indexes := make([]int, len(models))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	sort.SliceStable(indexes, func(i, j int) bool {
+		a, b := indexes[i], indexes[j]
+		if scores[a] != scores[b] {
+			return scores[a] > scores[b]
+		}
+		return sequences[a] < sequences[b]
+	})
+	sorted := make([]T, len(models))
+	for i, index := range indexes {
+		sorted[i] = models[index]
+	}
+	copy(models, sorted)
+}
+
+// ScoreModels sets the Score field of the models, if the model has one.
+func ScoreModels[T any](models []T, key string, ignoreCase bool) {
+	f := getRankFields(reflect.TypeOf((*T)(nil)).Elem())
+	if f.score < 0 {
+		return
+	}
+	for i := range models {
+		v := reflect.ValueOf(&models[i]).Elem()
+		s := v.Field(f.score)
+		switch s.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			s.SetInt(int64(f.getScore(v, key, ignoreCase)))
+		}
+	}
+}
+
+// rankQuery wraps the query to order the rows by the score of Score, then by sequence; the parameters of the score follow the parameters of the query.
+func (l GenericQuery[T]) rankQuery(query string, key string, params []interface{}) (string, []interface{}) {
+	param := func(value interface{}) string {
+		params = append(params, value)
+		return l.Build(len(params))
+	}
+	equal := func(column string, value string) string {
+		if l.IgnoreCase {
+			return fmt.Sprintf("lower(%s) = lower(%s)", column, param(value))
+		}
+		return fmt.Sprintf("%s = %s", column, param(value))
+	}
+	prefix := EscapeLike(key, l.Dialect) + "%"
+	contains := "%" + EscapeLike(key, l.Dialect) + "%"
+	order := fmt.Sprintf("case when %s then %d when %s then %d when %s or %s then %d when %s or %s then %d else 0 end desc",
+		equal("r__.code", key), scoreExactCode,
+		equal("r__.name", key), scoreExactName,
+		l.Dialect.Like("r__.code", param(prefix), l.IgnoreCase), l.Dialect.Like("r__.name", param(prefix), l.IgnoreCase), scorePrefix,
+		l.Dialect.Like("r__.code", param(contains), l.IgnoreCase), l.Dialect.Like("r__.name", param(contains), l.IgnoreCase), scoreContains)
+	if l.rank.sequence {
+		order = order + ", r__.sequence"
+	}
+	return fmt.Sprintf("select * from (%s) r__ order by %s", query, order), params
+}
+
+type rankSql struct {
+	enabled  bool
+	sequence bool
+}
+
+// getRankSql checks if the rows can be ranked in SQL: the result set must have the code and the name columns, and sql server does not allow order by in a derived table.
+func getRankSql(query string, dialect Dialect) rankSql {
+	columns, ok := selectColumns(query)
+	if !ok || !hasColumn(columns, "code", dialect) || !hasColumn(columns, "name", dialect) {
+		return rankSql{}
+	}
+	if dialect.Name() == driverMssql && hasKeywords(tokenize(query), "order", "by") {
+		return rankSql{}
+	}
+	return rankSql{enabled: true, sequence: hasColumn(columns, "sequence", dialect)}
+}
+
+// hasColumn reports whether the unquoted name, such as r__.code, refers to a column of the result set, as the database folds the unquoted names.
+func hasColumn(columns []selectColumn, name string, dialect Dialect) bool {
+	for _, column := range columns {
+		if column.quoted && column.name == dialect.Fold(name) || !column.quoted && strings.EqualFold(column.name, name) {
+			return true
+		}
+	}
+	return false
+}
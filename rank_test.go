@@ -0,0 +1,122 @@
+package code
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScore(t *testing.T) {
+	tests := []struct {
+		code       string
+		name       string
+		key        string
+		ignoreCase bool
+		want       int32
+	}{
+		{"HN", "Ha Noi", "HN", false, scoreExactCode},
+		{"HN", "Ha Noi", "ha noi", true, scoreExactName},
+		{"HN", "Ha Noi", "ha noi", false, 0},
+		{"HNX", "Ha Noi X", "HN", false, scorePrefix},
+		{"VHN", "Vinh", "HN", false, scoreContains},
+		{"SG", "Sai Gon", "HN", false, 0},
+	}
+	for _, tt := range tests {
+		if got := Score(tt.code, tt.name, tt.key, tt.ignoreCase); got != tt.want {
+			t.Errorf("Score(%q, %q, %q) = %d, want %d", tt.code, tt.name, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestRankModels(t *testing.T) {
+	models := []Model{
+		{Code: "VHN", Name: "Vinh", Sequence: 1},
+		{Code: "HNX", Name: "Ha Noi X", Sequence: 3},
+		{Code: "HNA", Name: "Ha Noi A", Sequence: 2},
+		{Code: "HN", Name: "Ha Noi", Sequence: 4},
+	}
+	RankModels(models, "hn", true)
+	if codes := getCodes(models); !equalStrings(codes, []string{"HN", "HNA", "HNX", "VHN"}) {
+		t.Errorf("RankModels = %v, want the exact code, then the prefixes by sequence", codes)
+	}
+	ScoreModels(models, "hn", true)
+	if models[0].Score != scoreExactCode || models[3].Score != scoreContains {
+		t.Errorf("ScoreModels = %d, %d, want %d, %d", models[0].Score, models[3].Score, scoreExactCode, scoreContains)
+	}
+
+	type place struct {
+		Code string
+		Name string
+	}
+	places := []place{{"A", "Ha Noi"}, {"B", "Hue"}}
+	RankModels(places, "Hue", false)
+	if places[0].Code != "B" {
+		t.Errorf("RankModels of other types = %v, want the exact name first", places)
+	}
+}
+
+func TestSelectColumns(t *testing.T) {
+	tests := []struct {
+		query   string
+		columns []selectColumn
+		ok      bool
+	}{
+		{"select code, name from t", []selectColumn{{name: "code"}, {name: "name"}}, true},
+		{"select c.code, c.name from t c", []selectColumn{{name: "code"}, {name: "name"}}, true},
+		{"select code as id, name from t where code like ?", []selectColumn{{name: "id"}, {name: "name"}}, true},
+		{"select code c, upper(name) name, 'x' kind from t", []selectColumn{{name: "c"}, {name: "name"}, {name: "kind"}}, true},
+		{`select "Code", [name] from t`, []selectColumn{{name: "Code", quoted: true}, {name: "name", quoted: true}}, true},
+		{"select a + code, (select max(s) from u) as sequence from t", []selectColumn{{}, {name: "sequence"}}, true},
+		{"select distinct top 10 code from t", []selectColumn{{name: "code"}}, true},
+		{"select * from t", nil, false},
+		{"select t.*, code from t", nil, false},
+	}
+	for _, tt := range tests {
+		columns, ok := selectColumns(tt.query)
+		if ok != tt.ok || (ok && !reflect.DeepEqual(columns, tt.columns)) {
+			t.Errorf("selectColumns(%q) = %v, %v, want %v, %v", tt.query, columns, ok, tt.columns, tt.ok)
+		}
+	}
+}
+
+func TestGetRankSql(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    rankSql
+	}{
+		{"code and name", PostgresDialect, "select code, name from t where name like ?", rankSql{enabled: true}},
+		{"sequence", PostgresDialect, "select code, name, sequence from t", rankSql{enabled: true, sequence: true}},
+		{"alias", MySqlDialect, "select id as code, label as name from t", rankSql{enabled: true}},
+		{"no name", PostgresDialect, "select code, label from t", rankSql{}},
+		{"star", PostgresDialect, "select * from t", rankSql{}},
+		{"quoted lower case", PostgresDialect, `select "code", "name" from t`, rankSql{enabled: true}},
+		{"quoted mixed case", PostgresDialect, `select "Code", "Name" from t`, rankSql{}},
+		{"quoted upper case", OracleDialect, `select "CODE", "NAME" from t`, rankSql{enabled: true}},
+		{"mssql order by", MsSqlDialect, "select code, name from t order by name", rankSql{}},
+		{"mssql subquery order by", MsSqlDialect, "select code, name from t where code in (select top 5 code from u order by code)", rankSql{enabled: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRankSql(tt.query, tt.dialect); got != tt.want {
+				t.Errorf("getRankSql(%q) = %+v, want %+v", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankQuery(t *testing.T) {
+	q, err := NewQueryWithDialect(nil, PostgresDialect, "select code, name, sequence from t where name like ?", "select code, name from t where code in", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query, params := q.rankQuery(q.Select, "h_", []interface{}{"h!_%"})
+	if !strings.HasPrefix(query, "select * from (select code, name, sequence from t where name like $1) r__ order by case when r__.code = $2") || !strings.HasSuffix(query, ", r__.sequence") {
+		t.Errorf("rankQuery = %q, want the query ordered by the score, then by sequence", query)
+	}
+	want := []interface{}{"h!_%", "h_", "h_", "h!_%", "h!_%", "%h!_%", "%h!_%"}
+	if !reflect.DeepEqual(params, want) {
+		t.Errorf("rankQuery params = %v, want %v", params, want)
+	}
+}
@@ -0,0 +1,218 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type SnapshotLoader struct {
+	LoadAll   func(ctx context.Context) (map[string][]Model, error)
+	Interval  time.Duration
+	Jitter    time.Duration
+	Error     func(context.Context, string, ...map[string]interface{})
+	mu        sync.RWMutex
+	codes     map[string][]Model
+	localized map[string]map[string][]Model
+	calls     map[string]*snapshotCall
+	refreshed time.Time
+	ready     chan struct{}
+	readyOnce sync.Once
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+type snapshotCall struct {
+	done  chan struct{}
+	codes map[string][]Model
+	err   error
+}
+
+func NewSnapshotLoader(loadAll func(ctx context.Context) (map[string][]Model, error), interval time.Duration, options ...time.Duration) *SnapshotLoader {
+	var jitter time.Duration
+	if len(options) > 0 {
+		jitter = options[0]
+	}
+	return &SnapshotLoader{LoadAll: loadAll, Interval: interval, Jitter: jitter, localized: make(map[string]map[string][]Model), calls: make(map[string]*snapshotCall), ready: make(chan struct{})}
+}
+func (l *SnapshotLoader) Start(ctx context.Context) error {
+	l.mu.Lock()
+	if l.stop != nil {
+		l.mu.Unlock()
+		return errors.New("snapshot loader is already started")
+	}
+	l.stop = make(chan struct{})
+	l.done = make(chan struct{})
+	stop, done := l.stop, l.done
+	l.mu.Unlock()
+	go func() {
+		defer close(done)
+		for {
+			if err := l.Refresh(ctx); err != nil && l.Error != nil {
+				l.Error(ctx, "cannot refresh code snapshot: "+err.Error())
+			}
+			var wait time.Duration
+			if l.IsReady() {
+				if l.Interval <= 0 {
+					return
+				}
+				wait = l.Interval
+				if l.Jitter > 0 {
+					wait = wait + time.Duration(rand.Int63n(int64(l.Jitter)))
+				}
+			} else {
+				wait = time.Second
+				if l.Interval > 0 && l.Interval < wait {
+					wait = l.Interval
+				}
+			}
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-stop:
+				timer.Stop()
+				return
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			}
+		}
+	}()
+	return nil
+}
+func (l *SnapshotLoader) Stop() {
+	l.mu.Lock()
+	stop, done := l.stop, l.done
+	l.stop, l.done = nil, nil
+	l.mu.Unlock()
+	if stop != nil {
+		close(stop)
+		<-done
+	}
+}
+func (l *SnapshotLoader) Refresh(ctx context.Context) error {
+	codes, err := l.LoadAll(ctx)
+	if err != nil {
+		return err
+	}
+	l.mu.Lock()
+	l.codes = codes
+	l.refreshed = time.Now()
+	locales := make([]string, 0, len(l.localized))
+	for locale := range l.localized {
+		locales = append(locales, locale)
+	}
+	l.mu.Unlock()
+	l.readyOnce.Do(func() {
+		close(l.ready)
+	})
+	for _, locale := range locales {
+		localized, er1 := l.LoadAll(WithLocale(ctx, locale))
+		if er1 != nil {
+			if err == nil {
+				err = er1
+			}
+			continue
+		}
+		l.mu.Lock()
+		l.localized[locale] = localized
+		l.mu.Unlock()
+	}
+	return err
+}
+func (l *SnapshotLoader) Ready() <-chan struct{} {
+	return l.ready
+}
+func (l *SnapshotLoader) IsReady() bool {
+	select {
+	case <-l.ready:
+		return true
+	default:
+		return false
+	}
+}
+func (l *SnapshotLoader) LastRefreshed() time.Time {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.refreshed
+}
+func (l *SnapshotLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	select {
+	case <-l.ready:
+	case <-ctx.Done():
+		return nil, ClassifyError(ctx.Err())
+	}
+	codes, err := l.getCodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	asOf, _ := GetAsOf(ctx)
+	models, ok := codes[master]
+	if !ok {
+		// the loaders without master, such as SqlLoader without master column, load the codes of all masters with the master ""
+		if v, ok := codes[""]; ok && !hasMaster(codes) {
+			models = v
+		}
+	}
+	// the masters without active codes are not in the snapshot, so an unknown master has no codes, as for SqlLoader
+	return copyModels(FilterEffective(models, asOf)), nil
+}
+
+func hasMaster(codes map[string][]Model) bool {
+	for master := range codes {
+		if len(master) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// getCodes returns the snapshot of the locale of the context. The snapshot of a locale is loaded by the first request in the locale, then refreshed with the default snapshot.
+func (l *SnapshotLoader) getCodes(ctx context.Context) (map[string][]Model, error) {
+	locale := GetLocale(ctx)
+	l.mu.RLock()
+	codes, ok := l.codes, true
+	if len(locale) > 0 {
+		codes, ok = l.localized[locale]
+	}
+	l.mu.RUnlock()
+	if ok {
+		return codes, nil
+	}
+	l.mu.Lock()
+	if l.localized == nil {
+		l.localized = make(map[string]map[string][]Model)
+		l.calls = make(map[string]*snapshotCall)
+	}
+	if codes, ok := l.localized[locale]; ok {
+		l.mu.Unlock()
+		return codes, nil
+	}
+	call, ok := l.calls[locale]
+	if !ok {
+		call = &snapshotCall{done: make(chan struct{})}
+		l.calls[locale] = call
+		lctx, cancel := detach(ctx)
+		go func() {
+			defer cancel()
+			codes, err := l.LoadAll(lctx)
+			l.mu.Lock()
+			delete(l.calls, locale)
+			if err == nil {
+				l.localized[locale] = codes
+			}
+			call.codes, call.err = codes, err
+			l.mu.Unlock()
+			close(call.done)
+		}()
+	}
+	l.mu.Unlock()
+	select {
+	case <-call.done:
+		return call.codes, call.err
+	case <-ctx.Done():
+		return nil, ClassifyError(ctx.Err())
+	}
+}
@@ -0,0 +1,131 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSnapshotLoader(t *testing.T) {
+	next := time.Now().Add(time.Hour)
+	var calls int32
+	l := NewSnapshotLoader(func(ctx context.Context) (map[string][]Model, error) {
+		atomic.AddInt32(&calls, 1)
+		name := "Female"
+		if locale := GetLocale(ctx); len(locale) > 0 {
+			name = name + " " + locale
+		}
+		return map[string][]Model{
+			"gender": {{Code: "F", Name: name}, {Code: "M", Name: "Male", ValidFrom: &next}},
+		}, nil
+	}, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.Load(ctx, "gender"); err == nil {
+		t.Error("Load before the first refresh, want an error")
+	}
+	if l.IsReady() {
+		t.Error("IsReady before the first refresh, want false")
+	}
+	if err := l.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !l.IsReady() || l.LastRefreshed().IsZero() {
+		t.Error("IsReady after the refresh, want true")
+	}
+	models, err := l.Load(context.Background(), "gender")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(models); !equalStrings(codes, []string{"F"}) {
+		t.Errorf("Load = %v, want the effective codes", codes)
+	}
+	models, _ = l.Load(WithAsOf(context.Background(), next), "gender")
+	if codes := getCodes(models); !equalStrings(codes, []string{"F", "M"}) {
+		t.Errorf("Load as of the valid from = %v, want all codes", codes)
+	}
+	models, _ = l.Load(context.Background(), "title")
+	if len(models) != 0 {
+		t.Errorf("Load of an unknown master = %v, want no codes", models)
+	}
+
+	models, _ = l.Load(WithLocale(context.Background(), "fr"), "gender")
+	if models[0].Name != "Female fr" {
+		t.Errorf("Load in fr = %q, want the snapshot of the locale", models[0].Name)
+	}
+	l.Load(WithLocale(context.Background(), "fr"), "gender")
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("calls = %d, want the snapshot of the locale loaded once", n)
+	}
+	l.Refresh(context.Background())
+	if n := atomic.LoadInt32(&calls); n != 4 {
+		t.Errorf("calls = %d, want the snapshot of the locale refreshed", n)
+	}
+}
+
+func TestSnapshotLoaderStart(t *testing.T) {
+	var calls int32
+	l := NewSnapshotLoader(func(ctx context.Context) (map[string][]Model, error) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			return nil, errors.New("unavailable")
+		}
+		return map[string][]Model{"gender": {{Code: "F"}}}, nil
+	}, 10*time.Millisecond)
+	var logged int32
+	l.Error = func(ctx context.Context, msg string, fields ...map[string]interface{}) {
+		atomic.AddInt32(&logged, 1)
+	}
+	if err := l.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := l.Start(context.Background()); err == nil {
+		t.Error("second Start, want an error")
+	}
+	select {
+	case <-l.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("Ready, want the snapshot after the failed refresh is retried")
+	}
+	time.Sleep(30 * time.Millisecond)
+	l.Stop()
+	n := atomic.LoadInt32(&calls)
+	if n < 3 {
+		t.Errorf("calls = %d, want the snapshot refreshed by the interval", n)
+	}
+	if atomic.LoadInt32(&logged) != 1 {
+		t.Errorf("logged = %d, want the failed refresh logged", logged)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if atomic.LoadInt32(&calls) != n {
+		t.Error("calls after Stop, want no refresh")
+	}
+}
+
+func TestSnapshotLoaderWithoutMaster(t *testing.T) {
+	tests := []struct {
+		name  string
+		codes map[string][]Model
+		want  []string
+	}{
+		{"codes without master", map[string][]Model{"": {{Code: "A"}}}, []string{"A"}},
+		{"master of empty name", map[string][]Model{"": {{Code: "A"}}, "gender": {{Code: "F"}}}, []string{}},
+		{"one master", map[string][]Model{"gender": {{Code: "F"}}}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewSnapshotLoader(func(ctx context.Context) (map[string][]Model, error) {
+				return tt.codes, nil
+			}, 0)
+			l.Refresh(context.Background())
+			models, err := l.Load(context.Background(), "status")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if codes := getCodes(models); !equalStrings(codes, tt.want) {
+				t.Errorf("Load of an unknown master = %v, want %v", codes, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package code
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	StaleHeader       = "X-Stale"
+	minRefreshTimeout = 30 * time.Second
+)
+
+type staleKey struct{}
+
+func WithStaleFlag(ctx context.Context) (context.Context, *bool) {
+	stale := false
+	return context.WithValue(ctx, staleKey{}, &stale), &stale
+}
+func markStale(ctx context.Context) {
+	if stale, ok := ctx.Value(staleKey{}).(*bool); ok && stale != nil {
+		*stale = true
+	}
+}
+
+type staleEntry struct {
+	master   string
+	models   []Model
+	loaded   time.Time
+	boundary time.Time
+}
+type StaleLoader struct {
+	Codes      func(ctx context.Context, master string) ([]Model, error)
+	MaxAge     time.Duration
+	Revalidate bool
+	Error      func(context.Context, string, ...map[string]interface{})
+	mu         sync.Mutex
+	entries    map[string]*staleEntry
+	refreshing map[string]bool
+}
+
+func NewStaleLoader(load func(ctx context.Context, master string) ([]Model, error), options ...time.Duration) *StaleLoader {
+	var maxAge time.Duration
+	if len(options) > 0 {
+		maxAge = options[0]
+	}
+	return &StaleLoader{Codes: load, MaxAge: maxAge, Revalidate: maxAge > 0, entries: make(map[string]*staleEntry), refreshing: make(map[string]bool)}
+}
+func (l *StaleLoader) Load(ctx context.Context, master string) ([]Model, error) {
+	if _, ok := GetAsOf(ctx); ok {
+		return l.Codes(ctx, master)
+	}
+	key := cacheKey(ctx, master)
+	if l.Revalidate {
+		l.mu.Lock()
+		entry, ok := l.entries[key]
+		// the codes are not served after the next boundary, when a code becomes valid or invalid
+		if ok && (entry.boundary.IsZero() || time.Now().Before(entry.boundary)) {
+			if time.Since(entry.loaded) < l.MaxAge {
+				l.mu.Unlock()
+				setBoundary(ctx, entry.boundary)
+				return copyModels(entry.models), nil
+			}
+			if !l.refreshing[key] {
+				l.refreshing[key] = true
+				go l.refresh(key, master, GetLocale(ctx))
+			}
+			l.mu.Unlock()
+			markStale(ctx)
+			return copyModels(entry.models), nil
+		}
+		l.mu.Unlock()
+	}
+	bctx, boundary := WithBoundary(ctx)
+	models, err := l.Codes(bctx, master)
+	if err != nil {
+		l.mu.Lock()
+		entry, ok := l.entries[key]
+		l.mu.Unlock()
+		if !ok {
+			return nil, err
+		}
+		if l.Error != nil {
+			l.Error(ctx, "serve stale codes of '"+master+"': "+err.Error())
+		}
+		markStale(ctx)
+		return copyModels(entry.models), nil
+	}
+	setBoundary(bctx, NextBoundary(models, time.Now()))
+	l.mu.Lock()
+	l.entries[key] = &staleEntry{master: master, models: copyModels(models), loaded: time.Now(), boundary: *boundary}
+	l.mu.Unlock()
+	setBoundary(ctx, *boundary)
+	return models, nil
+}
+func (l *StaleLoader) Remove(master string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, entry := range l.entries {
+		if entry.master == master {
+			delete(l.entries, key)
+		}
+	}
+}
+func (l *StaleLoader) Clear() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.entries = make(map[string]*staleEntry)
+}
+func (l *StaleLoader) refresh(key string, master string, locale string) {
+	// the refresh is bounded, else a hanging source would keep the codes stale forever
+	timeout := l.MaxAge
+	if timeout < minRefreshTimeout {
+		timeout = minRefreshTimeout
+	}
+	tctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if len(locale) > 0 {
+		tctx = WithLocale(tctx, locale)
+	}
+	ctx, boundary := WithBoundary(tctx)
+	models, err := l.Codes(ctx, master)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.refreshing, key)
+	if err != nil {
+		if l.Error != nil {
+			l.Error(ctx, "cannot refresh codes of '"+master+"': "+err.Error())
+		}
+		return
+	}
+	setBoundary(ctx, NextBoundary(models, time.Now()))
+	l.entries[key] = &staleEntry{master: master, models: models, loaded: time.Now(), boundary: *boundary}
+}
@@ -0,0 +1,131 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type staleSource struct {
+	mu      sync.Mutex
+	name    string
+	err     error
+	calls   int32
+	refresh chan struct{}
+}
+
+func (s *staleSource) Load(ctx context.Context, master string) ([]Model, error) {
+	atomic.AddInt32(&s.calls, 1)
+	s.mu.Lock()
+	name, err, refresh := s.name, s.err, s.refresh
+	s.mu.Unlock()
+	if refresh != nil {
+		defer func() { refresh <- struct{}{} }()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return []Model{{Code: master, Name: name}}, nil
+}
+func (s *staleSource) set(name string, err error) {
+	s.mu.Lock()
+	s.name, s.err = name, err
+	s.mu.Unlock()
+}
+
+func TestStaleLoader(t *testing.T) {
+	source := &staleSource{name: "v1"}
+	l := NewStaleLoader(source.Load, 20*time.Millisecond)
+	models, err := loadStale(l.Load, "gender")
+	if err != nil || models[0].Name != "v1" {
+		t.Fatalf("Load = %v, %v, want the codes of the source", models, err)
+	}
+	source.set("v2", nil)
+	if _, stale, _ := loadStaleFlag(l.Load, "gender"); stale {
+		t.Error("Load before the max age, want the codes not stale")
+	}
+	time.Sleep(30 * time.Millisecond)
+	source.mu.Lock()
+	source.refresh = make(chan struct{}, 1)
+	source.mu.Unlock()
+	models, stale, _ := loadStaleFlag(l.Load, "gender")
+	if !stale || models[0].Name != "v1" {
+		t.Errorf("Load after the max age = %q, %v, want the stale codes", models[0].Name, stale)
+	}
+	select {
+	case <-source.refresh:
+	case <-time.After(time.Second):
+		t.Fatal("refresh, want the codes refreshed in the background")
+	}
+	time.Sleep(5 * time.Millisecond)
+	models, stale, _ = loadStaleFlag(l.Load, "gender")
+	if stale || models[0].Name != "v2" {
+		t.Errorf("Load after the refresh = %q, %v, want the refreshed codes", models[0].Name, stale)
+	}
+}
+
+func TestStaleLoaderError(t *testing.T) {
+	source := &staleSource{name: "v1"}
+	l := NewStaleLoader(source.Load)
+	var logged int32
+	l.Error = func(ctx context.Context, msg string, fields ...map[string]interface{}) {
+		atomic.AddInt32(&logged, 1)
+	}
+	if _, err := loadStale(l.Load, "gender"); err != nil {
+		t.Fatal(err)
+	}
+	source.set("", errors.New("unavailable"))
+	models, stale, err := loadStaleFlag(l.Load, "gender")
+	if err != nil || !stale || models[0].Name != "v1" {
+		t.Errorf("Load when the source fails = %v, %v, %v, want the stale codes", models, stale, err)
+	}
+	if atomic.LoadInt32(&logged) != 1 {
+		t.Error("Load when the source fails, want the error logged")
+	}
+	if _, err := loadStale(l.Load, "title"); err == nil {
+		t.Error("Load of a master never loaded when the source fails, want an error")
+	}
+}
+
+func TestCacheLoaderStale(t *testing.T) {
+	source := &staleSource{name: "v1"}
+	stale := NewStaleLoader(source.Load)
+	if _, err := loadStale(stale.Load, "gender"); err != nil {
+		t.Fatal(err)
+	}
+	source.set("", errors.New("unavailable"))
+	c := NewCacheLoader(stale.Load, time.Minute)
+	var wg sync.WaitGroup
+	flags := make([]bool, 5)
+	for i := range flags {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, flags[i], _ = loadStaleFlag(c.Load, "gender")
+		}(i)
+	}
+	wg.Wait()
+	for i, flag := range flags {
+		if !flag {
+			t.Errorf("request %d, want the stale codes marked stale", i)
+		}
+	}
+	source.set("v2", nil)
+	models, flag, _ := loadStaleFlag(c.Load, "gender")
+	if flag || models[0].Name != "v2" {
+		t.Errorf("Load after the source is back = %q, %v, want the stale codes not cached", models[0].Name, flag)
+	}
+}
+
+func loadStale(load func(context.Context, string) ([]Model, error), master string) ([]Model, error) {
+	models, _, err := loadStaleFlag(load, master)
+	return models, err
+}
+func loadStaleFlag(load func(context.Context, string) ([]Model, error), master string) ([]Model, bool, error) {
+	ctx, stale := WithStaleFlag(context.Background())
+	models, err := load(ctx, master)
+	return models, *stale, err
+}
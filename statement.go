@@ -0,0 +1,149 @@
+package code
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+)
+
+const (
+	maxStatements = 256
+	maxInList     = 1000
+)
+
+var ErrClosed = errors.New("code loader is closed")
+
+// statements prepares the queries lazily, and keeps the prepared statements by query.
+type statements struct {
+	db     *sql.DB
+	mu     sync.Mutex
+	stmts  map[string]*preparedStmt
+	closed bool
+}
+
+// preparedStmt is ready when the query is prepared, so that the lock is not held while preparing.
+type preparedStmt struct {
+	ready chan struct{}
+	stmt  *sql.Stmt
+	err   error
+}
+
+func newStatements(db *sql.DB) *statements {
+	return &statements{db: db, stmts: make(map[string]*preparedStmt)}
+}
+
+// queryContext runs the query with the prepared statement, or without if the loader was not created by a constructor.
+func queryContext(ctx context.Context, db *sql.DB, s *statements, query string, args ...interface{}) (*sql.Rows, error) {
+	var rows *sql.Rows
+	var err error
+	if s == nil {
+		rows, err = db.QueryContext(ctx, query, args...)
+	} else {
+		rows, err = s.query(ctx, query, args...)
+	}
+	return rows, ClassifyError(err)
+}
+func (s *statements) query(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, ErrClosed
+	}
+	p, ok := s.stmts[query]
+	if !ok {
+		if len(s.stmts) >= maxStatements {
+			s.mu.Unlock()
+			// too many shapes of query, such as many different limits
+			return s.db.QueryContext(ctx, query, args...)
+		}
+		p = &preparedStmt{ready: make(chan struct{})}
+		s.stmts[query] = p
+		s.mu.Unlock()
+		stmt, err := s.db.PrepareContext(ctx, query)
+		s.mu.Lock()
+		p.stmt, p.err = stmt, err
+		if err != nil || s.closed {
+			// the query is prepared again by the next call
+			if s.stmts[query] == p {
+				delete(s.stmts, query)
+			}
+			if stmt != nil {
+				stmt.Close()
+			}
+			p.stmt = nil
+		}
+		close(p.ready)
+		closed := s.closed
+		s.mu.Unlock()
+		if err != nil {
+			return nil, err
+		}
+		if closed {
+			return nil, ErrClosed
+		}
+	} else {
+		s.mu.Unlock()
+		select {
+		case <-p.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if p.stmt == nil {
+		if p.err == nil {
+			return nil, ErrClosed
+		}
+		// the query could not be prepared by the other call, such as when its context is canceled
+		return s.db.QueryContext(ctx, query, args...)
+	}
+	return p.stmt.QueryContext(ctx, args...)
+}
+func (s *statements) Close() error {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	var err error
+	for query, p := range s.stmts {
+		select {
+		case <-p.ready:
+			if p.stmt != nil {
+				if er1 := p.stmt.Close(); er1 != nil && err == nil {
+					err = er1
+				}
+			}
+		default:
+			// the statement is closed when it is prepared
+		}
+		delete(s.stmts, query)
+	}
+	return err
+}
+
+// bucket returns the next power of 2, so that the in lists of different sizes share a few prepared statements.
+// The size is not padded over maxInList, the limit of the items of an in list of Oracle, nor are the larger lists.
+func bucket(n int) int {
+	if n > maxInList {
+		return n
+	}
+	size := 1
+	for size < n {
+		size = size * 2
+	}
+	if size > maxInList {
+		return maxInList
+	}
+	return size
+}
+func padKeys(keys []string) []string {
+	size := bucket(len(keys))
+	padded := make([]string, size)
+	copy(padded, keys)
+	for i := len(keys); i < size; i++ {
+		padded[i] = keys[len(keys)-1]
+	}
+	return padded
+}
@@ -0,0 +1,73 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	db, f := newFakeDB(fakeColumn{name: "code", dbType: "VARCHAR", scanType: textType})
+	s := newStatements(db)
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		rows, err := queryContext(ctx, db, s, "select code from codes where type = ?", "gender")
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+	}
+	if f.prepared != 1 {
+		t.Errorf("prepared = %d, want the statement prepared once", f.prepared)
+	}
+	for i := 0; i < maxStatements+10; i++ {
+		rows, err := queryContext(ctx, db, s, fmt.Sprintf("select code from codes limit %d", i+1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		rows.Close()
+	}
+	if open := f.prepared - f.closed; open != maxStatements {
+		t.Errorf("open statements = %d, want at most %d, the other queries not prepared", open, maxStatements)
+	}
+	if len(s.stmts) != maxStatements {
+		t.Errorf("cached statements = %d, want %d", len(s.stmts), maxStatements)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if f.prepared != f.closed {
+		t.Errorf("prepared = %d, closed = %d, want all the statements closed", f.prepared, f.closed)
+	}
+	if _, err := queryContext(ctx, db, s, "select code from codes where type = ?", "gender"); !errors.Is(err, ErrClosed) {
+		t.Errorf("query after Close error = %v, want ErrClosed", err)
+	}
+}
+
+func TestPadKeys(t *testing.T) {
+	tests := []struct {
+		n    int
+		size int
+	}{
+		{1, 1},
+		{3, 4},
+		{8, 8},
+		{9, 16},
+		{600, maxInList},
+		{maxInList + 1, maxInList + 1},
+	}
+	for _, tt := range tests {
+		keys := make([]string, tt.n)
+		for i := range keys {
+			keys[i] = fmt.Sprint(i)
+		}
+		padded := padKeys(keys)
+		if len(padded) != tt.size {
+			t.Errorf("padKeys of %d keys = %d keys, want %d", tt.n, len(padded), tt.size)
+		}
+		if padded[len(padded)-1] != keys[len(keys)-1] {
+			t.Errorf("padKeys of %d keys, want the keys padded with the last key", tt.n)
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package code
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+const (
+	TreeNested = "nested"
+	TreeFlat   = "flat"
+)
+
+var ErrCycle = errors.New("cycle in code hierarchy")
+
+type CycleError struct {
+	Key  string
+	Path []string
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("%s at '%s': %v", ErrCycle.Error(), e.Key, e.Path)
+}
+func (e *CycleError) Is(target error) bool {
+	return target == ErrCycle
+}
+
+type hierarchy struct {
+	models   []Model
+	index    map[string]int
+	children map[string][]int
+	roots    []int
+}
+
+// getKey returns the key that the parent of other codes refers to, the id if any, else the code.
+func getKey(m Model) string {
+	if len(m.Id) > 0 {
+		return m.Id
+	}
+	return m.Code
+}
+func newHierarchy(models []Model) *hierarchy {
+	h := &hierarchy{models: models, index: make(map[string]int), children: make(map[string][]int)}
+	for i, m := range models {
+		h.index[getKey(m)] = i
+	}
+	for i, m := range models {
+		if _, ok := h.index[m.Parent]; len(m.Parent) > 0 && ok {
+			h.children[m.Parent] = append(h.children[m.Parent], i)
+		} else {
+			h.roots = append(h.roots, i)
+		}
+	}
+	return h
+}
+func (h *hierarchy) check() error {
+	// 0: not visited, 1: in the current path, 2: done
+	state := make(map[string]int)
+	for _, m := range h.models {
+		path := make([]string, 0)
+		key := getKey(m)
+		for len(key) > 0 && state[key] == 0 {
+			state[key] = 1
+			path = append(path, key)
+			i, ok := h.index[key]
+			if !ok {
+				// an unknown parent is a root, not a part of a cycle
+				key = ""
+				break
+			}
+			key = h.models[i].Parent
+		}
+		if len(key) > 0 && state[key] == 1 {
+			return &CycleError{Key: key, Path: path}
+		}
+		for _, k := range path {
+			state[k] = 2
+		}
+	}
+	return nil
+}
+func (h *hierarchy) nest(i int) Model {
+	m := h.models[i]
+	m.Children = nil
+	for _, j := range h.children[getKey(m)] {
+		m.Children = append(m.Children, h.nest(j))
+	}
+	return m
+}
+func (h *hierarchy) flatten(i int, depth int32, models []Model) []Model {
+	m := h.models[i]
+	m.Depth = depth
+	m.Children = nil
+	models = append(models, m)
+	for _, j := range h.children[getKey(m)] {
+		models = h.flatten(j, depth+1, models)
+	}
+	return models
+}
+
+// BuildTree nests the codes under their parents. The codes without parent, or with an unknown parent, are the roots.
+func BuildTree(models []Model) ([]Model, error) {
+	h := newHierarchy(models)
+	if err := h.check(); err != nil {
+		return nil, err
+	}
+	roots := make([]Model, 0)
+	for _, i := range h.roots {
+		roots = append(roots, h.nest(i))
+	}
+	return roots, nil
+}
+
+// Flatten returns the codes in depth first order, where the depth of the roots is 0.
+func Flatten(models []Model) ([]Model, error) {
+	h := newHierarchy(models)
+	if err := h.check(); err != nil {
+		return nil, err
+	}
+	flat := make([]Model, 0)
+	for _, i := range h.roots {
+		flat = h.flatten(i, 0, flat)
+	}
+	return flat, nil
+}
+
+// Ancestors returns the ancestors of the code, from its parent to the root.
+func Ancestors(models []Model, key string) ([]Model, error) {
+	h := newHierarchy(models)
+	i, ok := h.index[key]
+	if !ok {
+		return nil, nil
+	}
+	ancestors := make([]Model, 0)
+	visited := map[string]bool{key: true}
+	path := []string{key}
+	for parent := h.models[i].Parent; len(parent) > 0; {
+		j, ok := h.index[parent]
+		if !ok {
+			break
+		}
+		if visited[parent] {
+			return nil, &CycleError{Key: parent, Path: path}
+		}
+		visited[parent] = true
+		path = append(path, parent)
+		ancestors = append(ancestors, h.models[j])
+		parent = h.models[j].Parent
+	}
+	return ancestors, nil
+}
+
+// Descendants returns the descendants of the code in depth first order, where the depth of its children is 1.
+func Descendants(models []Model, key string) ([]Model, error) {
+	h := newHierarchy(models)
+	if _, ok := h.index[key]; !ok {
+		return nil, nil
+	}
+	descendants := make([]Model, 0)
+	visited := map[string]bool{key: true}
+	var walk func(k string, depth int32, path []string) error
+	walk = func(k string, depth int32, path []string) error {
+		for _, j := range h.children[k] {
+			m := h.models[j]
+			child := getKey(m)
+			if visited[child] {
+				return &CycleError{Key: child, Path: append(path, child)}
+			}
+			visited[child] = true
+			m.Depth = depth
+			m.Children = nil
+			descendants = append(descendants, m)
+			if err := walk(child, depth+1, append(path, child)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := walk(key, 1, []string{key}); err != nil {
+		return nil, err
+	}
+	return descendants, nil
+}
+func GetTreeMode(r *http.Request, param string, mode string) (string, error) {
+	if len(param) > 0 {
+		if m := r.URL.Query().Get(param); len(m) > 0 {
+			mode = m
+		}
+	}
+	if len(mode) > 0 && mode != TreeNested && mode != TreeFlat {
+		return mode, invalidInput("tree mode '%s' is not supported", mode)
+	}
+	return mode, nil
+}
+func ToTree[T any](result []T, mode string) (interface{}, error) {
+	models, ok := interface{}(result).([]Model)
+	if !ok {
+		return nil, invalidInput("tree mode '%s' is only supported for Model", mode)
+	}
+	switch mode {
+	case TreeNested:
+		return BuildTree(models)
+	case TreeFlat:
+		return Flatten(models)
+	default:
+		return nil, invalidInput("tree mode '%s' is not supported", mode)
+	}
+}
+
+type HierarchyLoader struct {
+	Codes func(ctx context.Context, master string) ([]Model, error)
+}
+
+func NewHierarchyLoader(load func(ctx context.Context, master string) ([]Model, error)) *HierarchyLoader {
+	return &HierarchyLoader{Codes: load}
+}
+func (l HierarchyLoader) Tree(ctx context.Context, master string) ([]Model, error) {
+	models, err := l.Codes(ctx, master)
+	if err != nil {
+		return nil, err
+	}
+	return BuildTree(models)
+}
+func (l HierarchyLoader) Flat(ctx context.Context, master string) ([]Model, error) {
+	models, err := l.Codes(ctx, master)
+	if err != nil {
+		return nil, err
+	}
+	return Flatten(models)
+}
+func (l HierarchyLoader) Ancestors(ctx context.Context, master string, key string) ([]Model, error) {
+	models, err := l.Codes(ctx, master)
+	if err != nil {
+		return nil, err
+	}
+	return Ancestors(models, key)
+}
+func (l HierarchyLoader) Descendants(ctx context.Context, master string, key string) ([]Model, error) {
+	models, err := l.Codes(ctx, master)
+	if err != nil {
+		return nil, err
+	}
+	return Descendants(models, key)
+}
@@ -0,0 +1,109 @@
+package code
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func getTreeCodes() []Model {
+	return []Model{
+		{Code: "VN", Name: "Vietnam"},
+		{Code: "HN", Name: "Ha Noi", Parent: "VN"},
+		{Code: "BD", Name: "Ba Dinh", Parent: "HN"},
+		{Code: "SG", Name: "Sai Gon", Parent: "VN"},
+		{Code: "X", Name: "Unknown parent", Parent: "Y"},
+	}
+}
+
+func TestBuildTree(t *testing.T) {
+	roots, err := BuildTree(getTreeCodes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(roots); !equalStrings(codes, []string{"VN", "X"}) {
+		t.Fatalf("roots = %v, want the codes without parent or with an unknown parent", codes)
+	}
+	if codes := getCodes(roots[0].Children); !equalStrings(codes, []string{"HN", "SG"}) {
+		t.Errorf("children of VN = %v, want HN, SG", codes)
+	}
+	if codes := getCodes(roots[0].Children[0].Children); !equalStrings(codes, []string{"BD"}) {
+		t.Errorf("children of HN = %v, want BD", codes)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	flat, err := Flatten(getTreeCodes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(flat); !equalStrings(codes, []string{"VN", "HN", "BD", "SG", "X"}) {
+		t.Errorf("Flatten = %v, want the depth first order", codes)
+	}
+	depths := []int32{0, 1, 2, 1, 0}
+	for i, m := range flat {
+		if m.Depth != depths[i] {
+			t.Errorf("depth of %s = %d, want %d", m.Code, m.Depth, depths[i])
+		}
+	}
+}
+
+func TestAncestorsAndDescendants(t *testing.T) {
+	ancestors, err := Ancestors(getTreeCodes(), "BD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(ancestors); !equalStrings(codes, []string{"HN", "VN"}) {
+		t.Errorf("Ancestors = %v, want the parent to the root", codes)
+	}
+	descendants, err := Descendants(getTreeCodes(), "VN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if codes := getCodes(descendants); !equalStrings(codes, []string{"HN", "BD", "SG"}) {
+		t.Errorf("Descendants = %v, want the depth first order", codes)
+	}
+	if descendants[1].Depth != 2 {
+		t.Errorf("depth of BD = %d, want 2", descendants[1].Depth)
+	}
+}
+
+func TestCycleError(t *testing.T) {
+	models := []Model{
+		{Code: "A", Parent: "C"},
+		{Code: "B", Parent: "A"},
+		{Code: "C", Parent: "B"},
+		{Code: "D", Parent: "A"},
+	}
+	_, err := BuildTree(models)
+	var cycle *CycleError
+	if !errors.As(err, &cycle) || !errors.Is(err, ErrCycle) {
+		t.Fatalf("BuildTree error = %v, want CycleError", err)
+	}
+	if len(cycle.Path) != 3 {
+		t.Errorf("path = %v, want the codes of the cycle", cycle.Path)
+	}
+	if _, err = Flatten(models); !errors.Is(err, ErrCycle) {
+		t.Errorf("Flatten error = %v, want ErrCycle", err)
+	}
+	if _, err = Ancestors(models, "D"); !errors.Is(err, ErrCycle) {
+		t.Errorf("Ancestors error = %v, want ErrCycle", err)
+	}
+	if _, err = Descendants(models, "A"); !errors.Is(err, ErrCycle) {
+		t.Errorf("Descendants error = %v, want ErrCycle", err)
+	}
+}
+
+func TestToTree(t *testing.T) {
+	if _, err := ToTree(getTreeCodes(), TreeNested); err != nil {
+		t.Error(err)
+	}
+	_, err := ToTree([]string{"A"}, TreeNested)
+	if status, _ := GetErrorStatus(err); status != http.StatusBadRequest {
+		t.Errorf("status of a tree of other types = %d, want %d", status, http.StatusBadRequest)
+	}
+	_, err = ToTree(getTreeCodes(), "deep")
+	if status, _ := GetErrorStatus(err); status != http.StatusBadRequest {
+		t.Errorf("status of an unknown mode = %d, want %d", status, http.StatusBadRequest)
+	}
+}
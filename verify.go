@@ -0,0 +1,259 @@
+package code
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type VerifyError struct {
+	Problems []string
+}
+
+func (e *VerifyError) Error() string {
+	return "invalid code configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Verify checks that the table has the configured columns, and that the columns can be scanned into the model.
+func (l GenericSqlLoader[T]) Verify(ctx context.Context) error {
+	rows, err := l.DB.QueryContext(ctx, fmt.Sprintf("select * from %s where 1 = 0", l.table))
+	if err != nil {
+		return &VerifyError{Problems: []string{fmt.Sprintf("table '%s': %s", l.Table, err.Error())}}
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]*sql.ColumnType)
+	for _, t := range types {
+		columns[strings.ToLower(t.Name())] = t
+	}
+	problems := make([]string, 0)
+	check := func(key string, column string, alias string) {
+		name := columnName(column)
+		t, ok := columns[strings.ToLower(name)]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("column '%s' of config '%s' does not exist in table '%s'", name, key, l.Table))
+			return
+		}
+		if len(alias) == 0 {
+			return
+		}
+		if i, ok := l.colMap[l.fold(alias)]; ok {
+			field := l.modelType.Field(i)
+			if !isScannable(getScanType(t), field.Type) {
+				problems = append(problems, fmt.Sprintf("column '%s' of config '%s' with type %s cannot be scanned into field %s of type %s", name, key, t.DatabaseTypeName(), field.Name, field.Type))
+			} else if !canScanNull(t, field.Type) {
+				problems = append(problems, fmt.Sprintf("column '%s' of config '%s' is nullable, but field %s of type %s is not a pointer or a sql.Scanner", name, key, field.Name, field.Type))
+			}
+		}
+	}
+	c := l.Config
+	keys := []string{"master", "id", "code", "name", "value", "text", "status", "parent", "valid_from", "valid_to"}
+	configured := []string{c.Master, c.Id, c.Code, c.Name, c.Value, c.Text, c.Status, c.Parent, c.ValidFrom, c.ValidTo}
+	aliases := []string{"", "id", "code", "name", "value", "text", "", "parent", "valid_from", "valid_to"}
+	for i, key := range keys {
+		if len(configured[i]) > 0 {
+			check(key, configured[i], aliases[i])
+		}
+	}
+	if len(c.Sequence) > 0 {
+		for _, item := range strings.Split(c.Sequence, ",") {
+			item = strings.TrimSpace(item)
+			if i := strings.LastIndexAny(item, " \t"); i >= 0 {
+				item = strings.TrimSpace(item[:i])
+			}
+			check("sequence", item, "")
+		}
+	}
+	for _, attribute := range c.Attributes {
+		check("attributes", attribute, "")
+	}
+	for _, locale := range c.Locales {
+		check("locales", c.Name+"_"+strings.ToLower(strings.Replace(locale, "-", "_", -1)), "name")
+	}
+	if len(problems) > 0 {
+		return &VerifyError{Problems: problems}
+	}
+	return nil
+}
+func (l GenericSqlLoader[T]) fold(column string) string {
+	if l.Map != nil {
+		return l.Map(column)
+	}
+	return column
+}
+
+// Verify runs the query with null parameters, and checks that the columns can be scanned into the model.
+// The null parameters can be bound to the parameters of any type, and match no rows.
+func (l GenericDynamicSqlLoader[T]) Verify(ctx context.Context) error {
+	params := make([]interface{}, l.ParameterCount)
+	return verifyQuery(ctx, l.DB, "query", l.Query, params, l.colMap, l.modelType, l.Attributes)
+}
+
+// Verify runs the queries with null parameters, and checks that the columns can be scanned into the model.
+func (l GenericQuery[T]) Verify(ctx context.Context) error {
+	params := make([]interface{}, l.ParameterCount)
+	problems := make([]string, 0)
+	query := l.getSelect(l.Match).query
+	if l.Rank && l.rank.enabled {
+		query, params = l.rankQuery(query, "", params)
+	}
+	if err := verifyQuery(ctx, l.DB, "select", l.Dialect.Limit(query, 1, 0), params, l.colMap, l.modelType, l.Attributes); err != nil {
+		if e, ok := err.(*VerifyError); ok {
+			problems = append(problems, e.Problems...)
+		} else {
+			return err
+		}
+	}
+	get := l.Get + fmt.Sprintf(" (%s)", l.Build(1))
+	if err := verifyQuery(ctx, l.DB, "get", get, []interface{}{nil}, l.colMap, l.modelType, l.Attributes); err != nil {
+		if e, ok := err.(*VerifyError); ok {
+			problems = append(problems, e.Problems...)
+		} else {
+			return err
+		}
+	}
+	if len(problems) > 0 {
+		return &VerifyError{Problems: problems}
+	}
+	return nil
+}
+func verifyQuery(ctx context.Context, db *sql.DB, key string, query string, params []interface{}, colMap map[string]int, modelType reflect.Type, attributes []string) error {
+	rows, err := db.QueryContext(ctx, query, params...)
+	if err != nil {
+		return &VerifyError{Problems: []string{fmt.Sprintf("%s '%s': %s", key, query, err.Error())}}
+	}
+	defer rows.Close()
+	types, err := rows.ColumnTypes()
+	if err != nil {
+		return err
+	}
+	problems := make([]string, 0)
+	mapped := 0
+	for _, t := range types {
+		i, ok := colMap[t.Name()]
+		if !ok {
+			continue
+		}
+		mapped++
+		field := modelType.Field(i)
+		if !isScannable(getScanType(t), field.Type) {
+			problems = append(problems, fmt.Sprintf("column '%s' of %s with type %s cannot be scanned into field %s of type %s", t.Name(), key, t.DatabaseTypeName(), field.Name, field.Type))
+		} else if !canScanNull(t, field.Type) {
+			problems = append(problems, fmt.Sprintf("column '%s' of %s is nullable, but field %s of type %s is not a pointer or a sql.Scanner", t.Name(), key, field.Name, field.Type))
+		}
+	}
+	if mapped == 0 {
+		problems = append(problems, fmt.Sprintf("no column of %s is mapped to %s", key, modelType.Name()))
+	}
+	for _, attribute := range attributes {
+		found := false
+		for _, t := range types {
+			if strings.EqualFold(t.Name(), attribute) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			problems = append(problems, fmt.Sprintf("attribute '%s' is not a column of %s", attribute, key))
+		}
+	}
+	if len(problems) > 0 {
+		return &VerifyError{Problems: problems}
+	}
+	return nil
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+	bytesType   = reflect.TypeOf([]byte(nil))
+)
+
+// getScanType returns the scan type of the column, or the type of its database type name if the driver does not know it before reading a row.
+func getScanType(t *sql.ColumnType) reflect.Type {
+	scanType := t.ScanType()
+	if scanType != nil && scanType.Kind() != reflect.Interface {
+		return scanType
+	}
+	name := strings.ToUpper(t.DatabaseTypeName())
+	switch {
+	case strings.Contains(name, "INTERVAL"), strings.Contains(name, "POINT"):
+		return scanType
+	case strings.Contains(name, "INT"):
+		return reflect.TypeOf(int64(0))
+	case strings.Contains(name, "CHAR"), strings.Contains(name, "TEXT"), strings.Contains(name, "CLOB"):
+		return reflect.TypeOf("")
+	case strings.Contains(name, "DATE"), strings.Contains(name, "TIME"):
+		return timeType
+	case strings.Contains(name, "REAL"), strings.Contains(name, "FLOA"), strings.Contains(name, "DOUB"), strings.Contains(name, "NUM"), strings.Contains(name, "DEC"):
+		return reflect.TypeOf(float64(0))
+	case strings.Contains(name, "BOOL"):
+		return reflect.TypeOf(false)
+	case strings.Contains(name, "BLOB"), strings.Contains(name, "BINARY"), strings.Contains(name, "BYTEA"):
+		return bytesType
+	}
+	return scanType
+}
+
+// canScanNull reports whether a null of the column can be scanned into the field, if the driver knows that the column is nullable.
+func canScanNull(t *sql.ColumnType, fieldType reflect.Type) bool {
+	if nullable, ok := t.Nullable(); !ok || !nullable {
+		return true
+	}
+	return fieldType.Kind() == reflect.Ptr || fieldType.Kind() == reflect.Interface || reflect.PtrTo(fieldType).Implements(scannerType)
+}
+
+// isScannable reports whether the database/sql conversion of a column of the scan type into the field can succeed.
+func isScannable(scanType reflect.Type, fieldType reflect.Type) bool {
+	for fieldType.Kind() == reflect.Ptr {
+		fieldType = fieldType.Elem()
+	}
+	if reflect.PtrTo(fieldType).Implements(scannerType) || fieldType.Kind() == reflect.Interface {
+		return true
+	}
+	if scanType == nil || scanType.Kind() == reflect.Interface {
+		return true
+	}
+	switch scanType {
+	case reflect.TypeOf(sql.NullString{}):
+		scanType = reflect.TypeOf("")
+	case reflect.TypeOf(sql.NullInt64{}), reflect.TypeOf(sql.NullInt32{}), reflect.TypeOf(sql.NullInt16{}):
+		scanType = reflect.TypeOf(int64(0))
+	case reflect.TypeOf(sql.NullFloat64{}):
+		scanType = reflect.TypeOf(float64(0))
+	case reflect.TypeOf(sql.NullBool{}):
+		scanType = reflect.TypeOf(false)
+	case reflect.TypeOf(sql.NullTime{}):
+		scanType = timeType
+	case reflect.TypeOf(sql.RawBytes{}):
+		scanType = bytesType
+	}
+	for scanType.Kind() == reflect.Ptr {
+		scanType = scanType.Elem()
+	}
+	if fieldType == timeType {
+		return scanType == timeType
+	}
+	if scanType == timeType {
+		return fieldType.Kind() == reflect.String || fieldType == bytesType
+	}
+	switch fieldType.Kind() {
+	case reflect.String:
+		return true
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		switch scanType.Kind() {
+		case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64, reflect.String:
+			return true
+		}
+		return scanType == bytesType
+	case reflect.Slice:
+		return fieldType == bytesType && (scanType == bytesType || scanType.Kind() == reflect.String)
+	}
+	return false
+}
@@ -0,0 +1,196 @@
+package code
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type fakeColumn struct {
+	name     string
+	dbType   string
+	scanType reflect.Type
+	nullable bool
+}
+
+// fakeDB is a database/sql driver, which returns the columns for all queries, without rows, and records the queries and the prepared statements.
+type fakeDB struct {
+	mu       sync.Mutex
+	columns  []fakeColumn
+	queries  []string
+	args     [][]driver.Value
+	prepared int
+	closed   int
+}
+
+func newFakeDB(columns ...fakeColumn) (*sql.DB, *fakeDB) {
+	f := &fakeDB{columns: columns}
+	return sql.OpenDB(f), f
+}
+func (f *fakeDB) Connect(ctx context.Context) (driver.Conn, error) {
+	return &fakeConn{db: f}, nil
+}
+func (f *fakeDB) Driver() driver.Driver {
+	return nil
+}
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	if strings.Contains(query, "missing") {
+		return nil, errors.New("relation does not exist")
+	}
+	c.db.mu.Lock()
+	c.db.prepared++
+	c.db.mu.Unlock()
+	return &fakeStmt{db: c.db, query: query}, nil
+}
+func (c *fakeConn) Close() error {
+	return nil
+}
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+type fakeStmt struct {
+	db    *fakeDB
+	query string
+}
+
+func (s *fakeStmt) Close() error {
+	s.db.mu.Lock()
+	s.db.closed++
+	s.db.mu.Unlock()
+	return nil
+}
+func (s *fakeStmt) NumInput() int {
+	return -1
+}
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("not supported")
+}
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	s.db.queries = append(s.db.queries, s.query)
+	s.db.args = append(s.db.args, args)
+	s.db.mu.Unlock()
+	return &fakeRows{columns: s.db.columns}, nil
+}
+
+type fakeRows struct {
+	columns []fakeColumn
+}
+
+func (r *fakeRows) Columns() []string {
+	names := make([]string, 0)
+	for _, c := range r.columns {
+		names = append(names, c.name)
+	}
+	return names
+}
+func (r *fakeRows) Close() error {
+	return nil
+}
+func (r *fakeRows) Next(dest []driver.Value) error {
+	return io.EOF
+}
+func (r *fakeRows) ColumnTypeScanType(i int) reflect.Type {
+	return r.columns[i].scanType
+}
+func (r *fakeRows) ColumnTypeDatabaseTypeName(i int) string {
+	return r.columns[i].dbType
+}
+func (r *fakeRows) ColumnTypeNullable(i int) (bool, bool) {
+	return r.columns[i].nullable, true
+}
+
+var (
+	textType = reflect.TypeOf("")
+	intType  = reflect.TypeOf(int64(0))
+)
+
+func TestSqlLoaderVerify(t *testing.T) {
+	db, _ := newFakeDB(
+		fakeColumn{name: "type", dbType: "VARCHAR", scanType: textType},
+		fakeColumn{name: "code", dbType: "VARCHAR", scanType: textType},
+		fakeColumn{name: "name", dbType: "VARCHAR", scanType: textType, nullable: true},
+		fakeColumn{name: "sequence", dbType: "DATE", scanType: timeType},
+	)
+	config := StructureConfig{Master: "type", Code: "code", Name: "name", Sequence: "sequence", Status: "status", Active: "A"}
+	l, err := NewSqlCodeLoaderWithDialect(db, PostgresDialect, "codes", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = l.Verify(context.Background())
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) {
+		t.Fatalf("Verify error = %v, want VerifyError", err)
+	}
+	problems := []string{"column 'name' of config 'name' is nullable", "column 'status' of config 'status' does not exist"}
+	if len(verifyErr.Problems) != len(problems) {
+		t.Fatalf("Verify = %v, want %d problems", verifyErr.Problems, len(problems))
+	}
+	for i, problem := range problems {
+		if !strings.HasPrefix(verifyErr.Problems[i], problem) {
+			t.Errorf("problem %d = %q, want %q", i, verifyErr.Problems[i], problem)
+		}
+	}
+
+	l, _ = NewSqlCodeLoaderWithDialect(db, PostgresDialect, "missing", config)
+	if err = l.Verify(context.Background()); !errors.As(err, &verifyErr) || !strings.Contains(err.Error(), "table 'missing'") {
+		t.Errorf("Verify of a missing table = %v, want the table", err)
+	}
+}
+
+type verifyModel struct {
+	Code     string  `json:"code" gorm:"column:code"`
+	Name     *string `json:"name" gorm:"column:name"`
+	Sequence int32   `json:"sequence" gorm:"column:sequence"`
+}
+
+func TestQueryVerify(t *testing.T) {
+	db, f := newFakeDB(
+		fakeColumn{name: "code", dbType: "VARCHAR", scanType: textType},
+		fakeColumn{name: "name", dbType: "VARCHAR", scanType: textType, nullable: true},
+		fakeColumn{name: "sequence", dbType: "INT", scanType: intType},
+	)
+	q, err := NewGenericQueryWithDialect[verifyModel](db, PostgresDialect, "select code, name, sequence from codes where code like ? or name like ?", "select code, name, sequence from codes where code in", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = q.Verify(context.Background()); err != nil {
+		t.Errorf("Verify = %v, want no problem", err)
+	}
+	if len(f.args) != 2 || len(f.args[0]) != 2 || len(f.args[1]) != 1 {
+		t.Fatalf("args = %v, want the parameters of the select and the get queries", f.args)
+	}
+	for _, args := range f.args {
+		for _, arg := range args {
+			if arg != nil {
+				t.Errorf("args = %v, want null parameters, which can be bound to the parameters of any type", args)
+			}
+		}
+	}
+
+	db, _ = newFakeDB(
+		fakeColumn{name: "code", dbType: "VARCHAR", scanType: textType, nullable: true},
+		fakeColumn{name: "sequence", dbType: "VARCHAR", scanType: textType},
+	)
+	q, _ = NewGenericQueryWithDialect[verifyModel](db, PostgresDialect, "select code, sequence from codes where code like ?", "select code, sequence from codes where code in", 1)
+	err = q.Verify(context.Background())
+	var verifyErr *VerifyError
+	if !errors.As(err, &verifyErr) || len(verifyErr.Problems) != 2 {
+		t.Fatalf("Verify = %v, want the problems of the nullable code in both queries", err)
+	}
+	if !strings.Contains(verifyErr.Problems[0], "column 'code' of select is nullable") {
+		t.Errorf("problem = %q, want the nullable code", verifyErr.Problems[0])
+	}
+}